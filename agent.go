@@ -1,22 +1,31 @@
 package agogo
 
 import (
+	"encoding/gob"
+	"fmt"
 	"log"
+	"os"
 	"runtime"
 	"sync"
+	"time"
 
 	dual "github.com/gorgonia/agogo/dualnet"
 	"github.com/gorgonia/agogo/game"
 	"github.com/gorgonia/agogo/mcts"
+	"github.com/pkg/errors"
 )
 
-// An Agent is a player, AI or Human
+// An Agent is a Player that searches with MCTS, and evaluates positions with a neural network.
 type Agent struct {
 	NN     *dual.Dual
 	MCTS   *mcts.MCTS
 	Player game.Player
 	Enc    GameEncoder
 
+	// Cache caches the evaluations of NN across searches. Agents that use the same NN may share it. It is cleared when the agent
+	// switches to inference, as the NN may have changed.
+	Cache *mcts.EvalCache
+
 	// Statistics
 	Wins float32
 	Loss float32
@@ -28,6 +37,46 @@ type Agent struct {
 	inferer  chan Inferer
 	err      error
 	inferers []Inferer
+
+	// batched inference
+	batchWait time.Duration
+	batchSize int // the maximum size of a batch. If 0, it is the batch size of NN
+	batcher   *Batcher
+}
+
+// LoadAgent loads a neural network saved with AZ.Save, and creates an agent that plays g with it.
+func LoadAgent(g game.State, filename string, conf dual.Config, enc GameEncoder) (*Agent, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close()
+
+	nn := dual.New(conf)
+	if err = gob.NewDecoder(f).Decode(nn); err != nil {
+		return nil, errors.WithMessage(err, fmt.Sprintf("Unable to decode %v", filename))
+	}
+	retVal := &Agent{
+		NN:   nn,
+		Enc:  enc,
+		name: filename,
+	}
+	if err = retVal.SwitchToInference(g); err != nil {
+		return nil, err
+	}
+	return retVal, nil
+}
+
+// NewInfererAgent creates an agent that searches with inf instead of a neural network. It is used for agents that are not trained,
+// such as hand written evaluations.
+func NewInfererAgent(inf Inferer, enc GameEncoder) *Agent {
+	retVal := &Agent{
+		Enc:      enc,
+		inferer:  make(chan Inferer, 1),
+		inferers: []Inferer{inf},
+	}
+	retVal.inferer <- inf
+	return retVal
 }
 
 func newAgent(a Dualer) *Agent {
@@ -41,6 +90,8 @@ func newAgent(a Dualer) *Agent {
 // SwitchToInference uses the inference mode neural network.
 func (a *Agent) SwitchToInference(g game.State) (err error) {
 	a.Lock()
+	defer a.Unlock()
+	a.Cache.Clear()
 	a.inferer = make(chan Inferer, numCPU)
 
 	for i := 0; i < numCPU; i++ {
@@ -51,13 +102,26 @@ func (a *Agent) SwitchToInference(g game.State) (err error) {
 		a.inferers = append(a.inferers, inf)
 		a.inferer <- inf
 	}
+
+	if a.batchWait > 0 {
+		if err = a.closeBatcher(); err != nil {
+			return err
+		}
+		var inf *dual.Inferencer
+		if inf, err = dual.InferBatched(a.NN, a.batchSize, false); err != nil {
+			return err
+		}
+		a.batcher = NewBatcher(inf, a.Enc, inf.BatchSize(), a.batchWait)
+	}
 	// a.NN = nil // remove old NN
-	a.Unlock()
 	return nil
 }
 
 // Infer infers a bunch of moves based on the game state. This is mainly used to implement a Inferer such that the MCTS search can use it.
 func (a *Agent) Infer(g game.State) (policy []float32, value float32) {
+	if a.batcher != nil {
+		return a.batcher.Infer(g)
+	}
 	input := a.Enc(g)
 	inf := <-a.inferer
 
@@ -69,6 +133,8 @@ func (a *Agent) Infer(g game.State) (policy []float32, value float32) {
 		}
 		panic(err)
 	}
+	// the inferer may reuse its output buffer once it's returned to the pool
+	policy = append([]float32(nil), policy...)
 	a.inferer <- inf
 	return
 }
@@ -76,6 +142,14 @@ func (a *Agent) Infer(g game.State) (policy []float32, value float32) {
 // Search searches the game state and returns a suggested coordinate.
 func (a *Agent) Search(g game.State) game.Single {
 	a.MCTS.SetGame(g)
+	if a.batcher != nil {
+		n := runtime.NumCPU()
+		if a.MCTS.Deterministic {
+			n = 1
+		}
+		a.batcher.AddSearches(n)
+		defer a.batcher.AddSearches(-n)
+	}
 	return a.MCTS.Search(a.Player)
 }
 
@@ -96,13 +170,56 @@ func (a *Agent) Close() error {
 			allErrs = append(allErrs, err)
 		}
 	}
+	if err := a.closeBatcher(); err != nil {
+		allErrs = append(allErrs, err)
+	}
 	if len(allErrs) > 0 {
 		return allErrs
 	}
 	return nil
 }
 
+func (a *Agent) closeBatcher() error {
+	if a.batcher == nil {
+		return nil
+	}
+	err := a.batcher.Close()
+	a.batcher = nil
+	return err
+}
+
+// fork creates a new agent that plays on g with its own search tree, but shares the inferers of a.
+func (a *Agent) fork(g game.State, conf mcts.Config) *Agent {
+	retVal := &Agent{
+		NN:      a.NN,
+		Enc:     a.Enc,
+		name:    a.name,
+		inferer: a.inferer,
+		batcher: a.batcher,
+		Cache:   a.Cache,
+	}
+	retVal.MCTS = retVal.newMCTS(g, conf)
+	return retVal
+}
+
+// setCache makes the agent cache the evaluations of its NN in c.
+func (a *Agent) setCache(c *mcts.EvalCache) {
+	a.Cache = c
+	a.MCTS.SetEvalCache(c)
+}
+
+// newMCTS creates a search tree that plays g with the agent, and looks up evaluations in the agent's cache.
+func (a *Agent) newMCTS(g game.State, conf mcts.Config) *mcts.MCTS {
+	t := mcts.New(g, conf, a)
+	t.SetEvalCache(a.Cache)
+	return t
+}
+
 func (a *Agent) useDummy(g game.State) {
+	if err := a.closeBatcher(); err != nil {
+		log.Printf("Unable to close batcher: %v", err)
+	}
+	a.Cache.Clear()
 	a.inferer = make(chan Inferer, runtime.NumCPU())
 	for i := 0; i < runtime.NumCPU(); i++ {
 		a.inferer <- dummyInferer{
@@ -6,7 +6,6 @@ import (
 	"log"
 	"math/rand"
 	"os"
-	"time"
 
 	dual "github.com/gorgonia/agogo/dualnet"
 	"github.com/gorgonia/agogo/game"
@@ -23,6 +22,15 @@ type AZ struct {
 	Arena
 	Statistics
 	useDummy bool
+	replay   *ReplayBuffer
+	trainer  dual.Trainer
+
+	generation int // generation of the network of A. Every accepted network is a new generation
+	bgen       int // generation of the network of B: the same as A's if B plays with the same weights, or the next one for a candidate
+
+	// evaluation caches, keyed by the generation of the network they evaluate with. Agents on the same network share one
+	cacheSize int
+	caches    map[int]*mcts.EvalCache
 
 	// config
 	nnConf          dual.Config
@@ -30,10 +38,15 @@ type AZ struct {
 	enc             GameEncoder
 	aug             Augmenter
 	updateThreshold float32
+	gate            SPRT
 	maxExamples     int
+	sampling        SamplingStrategy
+	validationSplit float64
+	selfPlayWorkers int
 
 	// io
-	outEnc OutputEncoder
+	outEnc     OutputEncoder
+	checkpoint string
 }
 
 // New AlphaZero structure. It takes a game state (implementing the board, rules, etc.)
@@ -46,8 +59,18 @@ func New(g game.State, conf Config) *AZ {
 		panic("MCTSConf is not valid. Unable to proceed")
 	}
 
+	var r *rand.Rand
+	if conf.Seed != 0 {
+		r = mcts.NewRand(conf.Seed)
+		conf.MCTSConf.Seed = nextSeed(r)
+		conf.NNConf.Seed = nextSeed(r)
+	}
 	a := dual.New(conf.NNConf)
-	b := dual.New(conf.NNConf)
+	bconf := conf.NNConf
+	if r != nil {
+		bconf.Seed = nextSeed(r)
+	}
+	b := dual.New(bconf)
 
 	if err := a.Init(); err != nil {
 		panic(fmt.Sprintf("%+v", err))
@@ -64,16 +87,41 @@ func New(g game.State, conf Config) *AZ {
 		outEnc:          conf.OutputEncoder,
 		aug:             conf.Augmenter,
 		updateThreshold: float32(conf.UpdateThreshold),
+		gate:            conf.Gate,
 		maxExamples:     conf.MaxExamples,
+		sampling:        conf.ReplaySampling,
+		validationSplit: conf.ValidationSplit,
+		replay:          NewReplayBuffer(conf.ReplayWindow),
+		selfPlayWorkers: conf.SelfPlayWorkers,
 		Statistics:      makeStatistics(),
 		useDummy:        true,
+		checkpoint:      conf.Checkpoint,
+		bgen:            1,
+		cacheSize:       conf.EvalCacheSize,
+		caches:          make(map[int]*mcts.EvalCache),
 	}
+	retVal.trainer.Report = retVal.reportTraining
+	retVal.trainer.Patience = conf.Patience
+	if r != nil {
+		retVal.trainer.Seed = nextSeed(r)
+		retVal.replay.r = mcts.NewRand(nextSeed(r))
+	}
+	retVal.maxMoves = conf.MaxMoves
+	retVal.labeller = conf.ValueLabeller
+	retVal.A.batchWait = conf.BatchWait
+	retVal.B.batchWait = conf.BatchWait
+	retVal.A.batchSize = conf.batchSize()
+	retVal.B.batchSize = conf.batchSize()
+	retVal.setCaches()
 	retVal.logger = log.New(&retVal.buf, "", log.Ltime)
 	return retVal
 }
 
 func (a *AZ) setupSelfPlay(iter int) {
 	var err error
+	// switching to inference clears the caches of the agents, but their networks have not changed since the caches were filled
+	a.A.setCache(nil)
+	a.B.setCache(nil)
 	if err = a.A.SwitchToInference(a.game); err != nil {
 		// DO SOMETHING WITH ERROR
 	}
@@ -84,11 +132,43 @@ func (a *AZ) setupSelfPlay(iter int) {
 		log.Printf("Using Dummy")
 		a.A.useDummy(a.game)
 		a.B.useDummy(a.game)
+	} else {
+		// the evaluations of the dummy inferers are not cached, as they are not the networks'
+		a.setCaches()
 	}
 	log.Printf("Set up selfplay: Switch To inference for A. A.NN %p (%T)", a.A.NN, a.A.NN)
 	log.Printf("Set up selfplay: Switch To inference for B. B.NN %p (%T)", a.B.NN, a.B.NN)
 }
 
+// cache returns the evaluation cache of the network of generation gen.
+func (a *AZ) cache(gen int) *mcts.EvalCache {
+	c, ok := a.caches[gen]
+	if !ok {
+		c = mcts.NewEvalCache(a.cacheSize)
+		a.caches[gen] = c
+	}
+	return c
+}
+
+// setCaches gives A and B the caches of the generations of their networks, so that they share one when they play with the same network.
+// The caches of the other generations are dropped.
+func (a *AZ) setCaches() {
+	a.A.setCache(a.cache(a.generation))
+	a.B.setCache(a.cache(a.bgen))
+	for gen := range a.caches {
+		if gen != a.generation && gen != a.bgen {
+			delete(a.caches, gen)
+		}
+	}
+}
+
+// newCandidate gives B an empty cache for the candidate of the next generation, as its network has changed.
+func (a *AZ) newCandidate() {
+	a.bgen = a.generation + 1
+	delete(a.caches, a.bgen)
+	a.setCaches()
+}
+
 // SelfPlay plays an episode
 func (a *AZ) SelfPlay() []Example {
 	_, examples := a.Play(true, nil, a.aug) // don't encode images while selfplay... that'd be boring to watch
@@ -97,32 +177,37 @@ func (a *AZ) SelfPlay() []Example {
 }
 
 // Learn learns for iters. It self-plays for episodes, and then trains a new NN from the self play example.
+//
+// nniters is the number of passes of training through the examples. If a ValidationSplit is configured,
+// nniters is the maximum number of passes, and training stops early when the validation loss stops improving.
+//
+// If a checkpoint file is configured, a checkpoint is written at the end of every epoch.
 func (a *AZ) Learn(iters, episodes, nniters, arenaGames int) error {
+	return a.learn(0, iters, episodes, nniters, arenaGames)
+}
+
+func (a *AZ) learn(start, iters, episodes, nniters, arenaGames int) error {
 	var err error
-	for a.epoch = 0; a.epoch < iters; a.epoch++ {
-		var ex []Example
+	for a.epoch = start; a.epoch < iters; a.epoch++ {
 		log.Printf("Self Play for epoch %d. Player A %p, Player B %p", a.epoch, a.A, a.B)
 
 		a.buf.Reset()
 		a.logger.Printf("Self Play for epoch %d. Player A %p, Player B %p", a.epoch, a.A, a.B)
 		a.logger.SetPrefix("\t")
 		a.setupSelfPlay(a.epoch)
-		for e := 0; e < episodes; e++ {
-			log.Printf("\tEpisode %v", e)
-			a.logger.Printf("Episode %v\n", e)
-			ex = append(ex, a.SelfPlay()...)
-		}
+		ex := a.selfPlay(episodes)
 		a.logger.SetPrefix("")
 		a.buf.Reset()
 
-		if a.maxExamples > 0 && len(ex) > a.maxExamples {
-			shuffleExamples(ex)
-			ex = ex[:a.maxExamples]
-		}
+		a.replay.Push(ex)
+		ex = a.replay.Sample(a.maxExamples, a.sampling)
+		shuffleExamples(a.r, ex)
+		ex, validation := a.splitExamples(ex)
 		Xs, Policies, Values, batches := a.prepareExamples(ex)
 		if batches == 0 {
 			return errors.New("batches is nil, probably too few examples regarding the batchsize")
 		}
+		a.setValidation(validation)
 
 		// // create a new DualNet for B
 		// a.B.NN = dual.New(a.nnConf)
@@ -130,7 +215,8 @@ func (a *AZ) Learn(iters, episodes, nniters, arenaGames int) error {
 		// 	return errors.WithMessage(err, "Unable to create new DualNet for B")
 		// }
 
-		if err = dual.Train(a.B.NN, Xs, Policies, Values, batches, nniters); err != nil {
+		a.newCandidate()
+		if err = a.trainer.Train(a.B.NN, Xs, Policies, Values, batches, nniters); err != nil {
 			return errors.WithMessage(err, fmt.Sprintf("Train fail"))
 		}
 
@@ -145,32 +231,56 @@ func (a *AZ) Learn(iters, episodes, nniters, arenaGames int) error {
 			a.logger.Printf("Playing game number %d", a.gameNumber)
 			a.Play(false, a.outEnc, nil)
 			a.game.Reset()
+			if a.gated() {
+				a.gameNumber++
+				break
+			}
 		}
 		a.logger.SetPrefix("")
 
 		var killedA bool
 		log.Printf("A wins %v, loss %v, draw %v\nB wins %v, loss %v, draw %v", a.A.Wins, a.A.Loss, a.A.Draw, a.B.Wins, a.B.Loss, a.B.Draw)
 
-		// if a.B.Wins/(a.B.Wins+a.B.Loss+a.B.Draw) > a.updateThreshold {
-		if a.B.Wins/(a.B.Wins+a.A.Wins) > a.updateThreshold {
+		if a.accept() {
 			// B wins. Kill A, clean up its resources.
 			log.Printf("Kill A %p. New A's NN is %p", a.A.NN, a.B.NN)
 			if err = a.A.Close(); err != nil {
 				return err
 			}
 			a.A.NN = a.B.NN
-			// clear examples
-			ex = ex[:0]
 			killedA = true
 		}
-		a.update(a.A)
+		a.update(a.generation, a.A)
+		if killedA {
+			a.generation++
+			r := a.rate(a.generation, a.epoch, a.B.Wins, a.B.Loss, a.B.Draw)
+			log.Printf("Generation %d accepted. Elo %.1f (95%% CI %.1f to %.1f)", r.Generation, r.Elo, r.Low, r.High)
+		}
 		if err = a.newB(a.nnConf, killedA); err != nil {
 			return err
 		}
+		a.newCandidate()
+		if a.checkpoint != "" {
+			if err = a.SaveCheckpoint(a.checkpoint); err != nil {
+				return errors.WithMessage(err, "Unable to save checkpoint")
+			}
+		}
 	}
 	return nil
 }
 
+// reportTraining records the metrics of every batch and every pass of training into the statistics. Only the passes are logged.
+func (a *AZ) reportTraining(m dual.Metrics) {
+	a.recordTraining(a.epoch, m)
+	if !m.IsPass() {
+		return
+	}
+	log.Printf("\tTraining epoch %d pass %d. Loss %v (policy %v, value %v, L2 %v). Gradient norm %v", a.epoch, m.Iteration, m.Loss, m.PolicyLoss, m.ValueLoss, m.L2, m.GradNorm)
+	if a.trainer.ValBatches > 0 {
+		log.Printf("\tValidation loss %v (policy %v, value %v)", m.ValLoss, m.ValPolicyLoss, m.ValValueLoss)
+	}
+}
+
 // Save learning into filenamee
 func (a *AZ) Save(filename string) error {
 	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0544)
@@ -205,11 +315,34 @@ func (a *AZ) Load(filename string) error {
 		return errors.WithStack(err)
 	}
 	a.useDummy = false
+	// B plays with the same network as A until it is trained
+	a.bgen = a.generation
+	a.caches = make(map[int]*mcts.EvalCache)
+	a.setCaches()
 	return nil
 }
 
+// splitExamples holds out the last ValidationSplit fraction of the (shuffled) examples for validation.
+// There is no validation data if the held out examples would not fill a batch.
+func (a *AZ) splitExamples(examples []Example) (train, validation []Example) {
+	held := int(a.validationSplit * float64(len(examples)))
+	if held < a.nnConf.BatchSize {
+		return examples, nil
+	}
+	split := len(examples) - held
+	return examples[:split], examples[split:]
+}
+
+// setValidation sets the validation data of the trainer.
+func (a *AZ) setValidation(validation []Example) {
+	if len(validation) == 0 {
+		a.trainer.ValXs, a.trainer.ValPolicies, a.trainer.ValValues, a.trainer.ValBatches = nil, nil, nil, 0
+		return
+	}
+	a.trainer.ValXs, a.trainer.ValPolicies, a.trainer.ValValues, a.trainer.ValBatches = a.prepareExamples(validation)
+}
+
 func (a *AZ) prepareExamples(examples []Example) (Xs, Policies, Values *tensor.Dense, batches int) {
-	shuffleExamples(examples)
 	batches = len(examples) / a.nnConf.BatchSize
 	total := batches * a.nnConf.BatchSize
 	var XsBacking, PoliciesBacking, ValuesBacking []float32
@@ -248,8 +381,7 @@ func (a *AZ) prepareExamples(examples []Example) (Xs, Policies, Values *tensor.D
 	return
 }
 
-func shuffleExamples(examples []Example) {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+func shuffleExamples(r *rand.Rand, examples []Example) {
 	for i := range examples {
 		j := r.Intn(i + 1)
 		examples[i], examples[j] = examples[j], examples[i]
@@ -7,12 +7,12 @@ import (
 	"log"
 	"math/rand"
 	"runtime"
-	"time"
 
 	"github.com/chewxy/math32"
 	dual "github.com/gorgonia/agogo/dualnet"
 	"github.com/gorgonia/agogo/game"
 	"github.com/gorgonia/agogo/mcts"
+	"github.com/pkg/errors"
 )
 
 // Arena represents a game arena
@@ -23,10 +23,12 @@ type Arena struct {
 	A, B *Agent
 
 	// state
-	currentPlayer *Agent
-	conf          mcts.Config
-	buf           bytes.Buffer
-	logger        *log.Logger
+	conf      mcts.Config
+	buf       bytes.Buffer
+	logger    *log.Logger
+	alternate bool          // if true, A plays black in even numbered games and white in odd numbered games. Otherwise colours are random
+	maxMoves  int           // if > 0, games are stopped after this many moves, and adjudicated by score
+	labeller  ValueLabeller // labels the value targets of the examples. If nil, OutcomeLabeller is used
 
 	// only relevant to training
 	name       string
@@ -45,20 +47,18 @@ func MakeArena(g game.State, a, b Dualer, conf mcts.Config, enc GameEncoder, aug
 		Enc:  enc,
 		name: "A",
 	}
-	A.MCTS = mcts.New(g, conf, A)
 	B := &Agent{
 		NN:   b.Dual(),
 		Enc:  enc,
 		name: "B",
 	}
-	B.MCTS = mcts.New(g, conf, B)
 
 	if name == "" {
 		name = "UNKNOWN GAME"
 	}
 
-	return Arena{
-		r:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	retVal := Arena{
+		r:    mcts.NewRand(conf.Seed),
 		game: g,
 		A:    A,
 		B:    B,
@@ -67,6 +67,9 @@ func MakeArena(g game.State, a, b Dualer, conf mcts.Config, enc GameEncoder, aug
 
 		oldThresh: 10,
 	}
+	A.MCTS = A.newMCTS(g, retVal.mctsConf())
+	B.MCTS = B.newMCTS(g, retVal.mctsConf())
+	return retVal
 }
 
 // NewArena makes an arena an returns a pointer to the Arena
@@ -76,106 +79,197 @@ func NewArena(g game.State, a, b Dualer, conf mcts.Config, enc GameEncoder, aug
 	return &ar
 }
 
-// Play plays a game, and retrns a winner. If it is a draw, the returned colour is None.
-func (a *Arena) Play(record bool, enc OutputEncoder, aug Augmenter) (winner game.Player, examples []Example) {
-	if a.r.Intn(2) == 0 {
-		a.A.Player = game.Player(game.Black)
-		a.B.Player = game.Player(game.White)
-		a.currentPlayer = a.A
+// fork creates a copy of the arena that has its own game state and search trees.
+// The agents of the copy share the neural network inferers with the agents of the original arena,
+// so both arenas can play at the same time.
+func (a *Arena) fork() *Arena {
+	g := a.game.Clone()
+	retVal := &Arena{
+		r:          rand.New(rand.NewSource(a.r.Int63())),
+		game:       g,
+		A:          a.A.fork(g, a.mctsConf()),
+		B:          a.B.fork(g, a.mctsConf()),
+		conf:       a.conf,
+		name:       a.name,
+		epoch:      a.epoch,
+		gameNumber: a.gameNumber,
+		oldThresh:  a.oldThresh,
+		alternate:  a.alternate,
+		maxMoves:   a.maxMoves,
+		labeller:   a.labeller,
+	}
+	retVal.logger = log.New(&retVal.buf, "", log.Ltime)
+	return retVal
+}
+
+// Play plays a game between A and B and returns its record. If record is true, it also returns the examples of the game,
+// with the value targets given by the labeller of the arena.
+func (a *Arena) Play(record bool, enc OutputEncoder, aug Augmenter) (rec GameRecord, examples []Example) {
+	var aIsBlack bool
+	if a.alternate {
+		aIsBlack = a.gameNumber%2 == 0
 	} else {
-		a.A.Player = game.Player(game.White)
-		a.B.Player = game.Player(game.Black)
-		a.currentPlayer = a.B
+		aIsBlack = a.r.Intn(2) == 0
+	}
+	black, white := a.A, a.B
+	if !aIsBlack {
+		black, white = a.B, a.A
 	}
 
-	a.game.SetToMove(a.currentPlayer.Player)
+	// root noise is for exploration in self play only
+	a.A.MCTS.SetNoise(record)
+	a.B.MCTS.SetNoise(record)
 	a.logger.Printf("Playing. Recording %t\n", record)
+	rec, examples, err := a.play(black, white, record, enc)
+	if err != nil {
+		// agents do not fail to play
+		panic(fmt.Sprintf("%+v", err))
+	}
+	if enc != nil {
+		log.Printf("\tDone playing")
+	}
+
+	if aug != nil {
+		augmented := make([]Example, 0, len(examples))
+		for _, ex := range examples {
+			augmented = append(augmented, aug(ex)...)
+		}
+		examples = augmented
+	}
+
+	if !record {
+		var winningAgent *Agent
+		switch rec.Winner {
+		case a.A.Player:
+			winningAgent = a.A
+		case a.B.Player:
+			winningAgent = a.B
+		}
+		log.Printf("Winner %v by %v | %p", rec.Winner, rec.Termination, winningAgent)
+	}
+	a.A.MCTS = a.A.newMCTS(a.game, a.mctsConf())
+	a.B.MCTS = a.B.newMCTS(a.game, a.mctsConf())
+	runtime.GC()
+	return rec, examples
+}
+
+// Match plays a game between any two players, and returns its record.
+func (a *Arena) Match(black, white Player, enc OutputEncoder) (GameRecord, error) {
+	rec, _, err := a.play(black, white, false, enc)
+	return rec, err
+}
+
+// play plays a game between black and white. If record is true, it also returns an example of every move of the agents,
+// with the value targets given by the labeller of the arena.
+func (a *Arena) play(black, white Player, record bool, enc OutputEncoder) (rec GameRecord, examples []Example, err error) {
+	rec.Black, rec.White = black.Name(), white.Name()
+	players := []Player{black, white}
+	colours := []game.Player{game.Player(game.Black), game.Player(game.White)}
+	a.game.SetToMove(game.Player(game.Black))
+	for i, p := range players {
+		if err = p.Start(a.game, colours[i]); err != nil {
+			return rec, nil, errors.WithMessage(err, fmt.Sprintf("%v failed to start a game", p.Name()))
+		}
+	}
+
 	a.logger.SetPrefix("\t\t")
-	var ended bool
 	var passCount int
-	for ended, winner = a.game.Ended(); !ended; ended, winner = a.game.Ended() {
+	var moves []int // the move of every example
+	for {
+		if ended, winner := a.game.Ended(); ended {
+			rec.Winner, rec.Termination = winner, a.termination(winner)
+			break
+		}
+		if a.maxMoves > 0 && len(rec.Moves) >= a.maxMoves {
+			rec.Winner, rec.Termination = adjudicate(a.game), MoveLimit
+			break
+		}
 
-		best := a.currentPlayer.Search(a.game)
+		colour := a.game.ToMove()
+		current := white
+		if colour == game.Player(game.Black) {
+			current = black
+		}
+		var best game.Single
+		if best, err = current.Move(a.game); err != nil {
+			return rec, nil, errors.WithMessage(err, fmt.Sprintf("%v failed to move", current.Name()))
+		}
+		var search mcts.SearchResult
+		if an, ok := current.(analyser); ok {
+			search = an.Analysis()
+		}
+		move := game.PlayerMove{Player: colour, Single: best}
+		rec.Moves = append(rec.Moves, move)
+		rec.Searches = append(rec.Searches, search)
+		a.logger.Printf("Current Player: %v. Best Move %v\n", colour, best)
+		if best.IsResignation() {
+			rec.Winner, rec.Termination = opponent(colour), Resignation
+			break
+		}
 		if best.IsPass() {
 			passCount++
 		} else {
 			passCount = 0
 		}
-		a.logger.Printf("Current Player: %v. Best Move %v\n", a.currentPlayer.Player, best)
-		if record {
-			boards := a.currentPlayer.Enc(a.game)
-			policies := a.currentPlayer.MCTS.Policies(a.game)
-			ex := Example{
-				Board:  boards,
-				Policy: policies,
-				// THIS IS A HACK.
-				// The value is 1 or -1 depending on player colour, but for now we store the player colour
-				Value: float32(a.currentPlayer.Player),
+		if agent, ok := current.(*Agent); ok && record {
+			policies := search.Policy(a.game.ActionSpace(), a.conf.PolicyTemperature)
+			if policies != nil && validPolicies(policies) {
+				examples = append(examples, Example{
+					Board:  agent.Enc(a.game),
+					Policy: policies,
+				})
+				moves = append(moves, len(rec.Moves)-1)
 			}
-			if validPolicies(policies) {
-				if aug != nil {
-					examples = append(examples, aug(ex)...)
-				} else {
-					examples = append(examples, ex)
-				}
-			}
-
 		}
 
-		// policy, value := a.currentPlayer.Infer(a.game)
-		// log.Printf("\t\tPlayer %v made Move %v | %1.1v %1.1v", a.currentPlayer.Player, best, policy, value)
-		a.game = a.game.Apply(game.PlayerMove{
-			Player: a.currentPlayer.Player,
-			Single: best,
-		})
-		a.switchPlayer()
+		a.game = a.game.Apply(move)
+		a.game.SetToMove(opponent(colour))
+		for _, p := range players {
+			if err = p.Played(move); err != nil {
+				return rec, nil, errors.WithMessage(err, fmt.Sprintf("%v failed to play %v", p.Name(), move))
+			}
+		}
 		if enc != nil {
 			enc.Encode(a)
 		}
 		if passCount >= 2 {
+			rec.Winner, rec.Termination = adjudicate(a.game), DoublePass
 			break
 		}
 	}
+	rec.BlackScore = a.game.Score(game.Player(game.Black))
+	rec.WhiteScore = a.game.Score(game.Player(game.White))
 	a.logger.SetPrefix("\t")
-	a.A.MCTS.Reset()
-	a.B.MCTS.Reset()
-	if enc != nil {
-		log.Printf("\tDone playing")
-	}
 
+	labeller := a.labeller
+	if labeller == nil {
+		labeller = OutcomeLabeller
+	}
 	for i := range examples {
-		switch {
-		case winner == game.Player(game.None):
-			examples[i].Value = 0
-		case examples[i].Value == float32(winner):
-			examples[i].Value = 1
-		default:
-			examples[i].Value = -1
-		}
-	}
-	var winningAgent *Agent
-	switch {
-	case winner == game.Player(game.None):
-		a.A.Draw++
-		a.B.Draw++
-	case winner == a.A.Player:
-		a.A.Wins++
-		a.B.Loss++
-		winningAgent = a.A
-	case winner == a.B.Player:
-		a.B.Wins++
-		a.A.Loss++
-		winningAgent = a.B
+		examples[i].Value = labeller(rec, moves[i])
 	}
-	if !record {
-		log.Printf("Winner %v | %p", winner, winningAgent)
+	for _, p := range players {
+		if err = p.End(rec); err != nil {
+			return rec, examples, errors.WithMessage(err, fmt.Sprintf("%v failed to end the game", p.Name()))
+		}
 	}
-	// a.A.MCTS.Reset()
-	// a.B.MCTS.Reset()
-	a.A.MCTS = mcts.New(a.game, a.conf, a.A)
-	a.B.MCTS = mcts.New(a.game, a.conf, a.B)
-	runtime.GC()
-	return game.Player(game.None), examples
+	return rec, examples, nil
+}
+
+// mctsConf returns the configuration of a new search tree. If the arena is seeded, every tree gets its own seed, drawn from the arena.
+func (a *Arena) mctsConf() mcts.Config {
+	conf := a.conf
+	if conf.Seed != 0 {
+		conf.Seed = nextSeed(a.r)
+	}
+	return conf
+}
+
+// reseed reseeds the arena and the search trees of its agents, so that the next game only depends on seed.
+func (a *Arena) reseed(seed int64) {
+	a.r.Seed(seed)
+	a.A.MCTS = a.A.newMCTS(a.game, a.mctsConf())
+	a.B.MCTS = a.B.newMCTS(a.game, a.mctsConf())
 }
 
 // Epoch returns the current Epoch
@@ -215,6 +309,9 @@ func (a *Arena) newB(conf dual.Config, killedA bool) (err error) {
 	// 	a.B.NN, err = a.B.NN.Clone()
 	// }
 
+	if conf.Seed != 0 {
+		conf.Seed = nextSeed(a.r)
+	}
 	a.B.NN = dual.New(conf)
 	err = a.B.NN.Init()
 
@@ -223,15 +320,6 @@ func (a *Arena) newB(conf dual.Config, killedA bool) (err error) {
 	return err
 }
 
-func (a *Arena) switchPlayer() {
-	switch a.currentPlayer {
-	case a.A:
-		a.currentPlayer = a.B
-	case a.B:
-		a.currentPlayer = a.A
-	}
-}
-
 func cloneBoard(a []game.Colour) []game.Colour {
 	retVal := make([]game.Colour, len(a))
 	copy(retVal, a)
@@ -0,0 +1,164 @@
+package agogo
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/mcts"
+	"github.com/pkg/errors"
+)
+
+var _ mcts.Inferencer = &Batcher{}
+
+// Batcher is an inference service that collects leaf evaluations from concurrent searches,
+// and evaluates them in one forward pass of the neural network.
+//
+// A batch is evaluated as soon as it is full, or when maxWait has passed since the first request of the batch arrived,
+// whichever comes first. A batch is also full when every search in flight has submitted a board (see AddSearches).
+//
+// Batcher implements mcts.Inferencer.
+type Batcher struct {
+	enc      GameEncoder
+	inf      BatchInferer
+	size     int
+	searches int32 // number of searches in flight
+	maxWait  time.Duration
+
+	requests chan batchRequest
+	wake     chan struct{} // signals a change of the number of searches in flight
+	done     chan struct{}
+}
+
+type batchRequest struct {
+	input []float32
+	ret   chan batchResult
+}
+
+type batchResult struct {
+	policy []float32
+	value  float32
+	err    error
+}
+
+// NewBatcher creates a new Batcher, which evaluates batches of up to size boards with inf.
+func NewBatcher(inf BatchInferer, enc GameEncoder, size int, maxWait time.Duration) *Batcher {
+	if size < 1 {
+		size = 1
+	}
+	retVal := &Batcher{
+		enc:      enc,
+		inf:      inf,
+		size:     size,
+		maxWait:  maxWait,
+		requests: make(chan batchRequest, size),
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go retVal.start()
+	return retVal
+}
+
+// AddSearches adds n, which may be negative, to the number of searches in flight on the batcher.
+// A batch is evaluated as soon as every search in flight has submitted a board, up to the size given to NewBatcher,
+// since no more boards may arrive before one of them is answered. If no search is counted, a batch waits to be full.
+func (b *Batcher) AddSearches(n int) {
+	atomic.AddInt32(&b.searches, int32(n))
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+// full returns the number of boards in a full batch.
+func (b *Batcher) full() int {
+	if n := int(atomic.LoadInt32(&b.searches)); n > 0 && n < b.size {
+		return n
+	}
+	return b.size
+}
+
+// Infer infers the policy and value of the game state. It blocks until the batch that the request is in has been evaluated.
+func (b *Batcher) Infer(g game.State) (policy []float32, value float32) {
+	req := batchRequest{
+		input: b.enc(g),
+		ret:   make(chan batchResult, 1),
+	}
+	b.requests <- req
+	res := <-req.ret
+	if res.err != nil {
+		if el, ok := b.inf.(ExecLogger); ok {
+			log.Println(el.ExecLog())
+		}
+		panic(res.err)
+	}
+	return res.policy, res.value
+}
+
+// Close stops the batcher and closes the underlying inferer. Close must not be called while there are searches in flight.
+func (b *Batcher) Close() error {
+	close(b.requests)
+	<-b.done
+	return b.inf.Close()
+}
+
+func (b *Batcher) start() {
+	defer close(b.done)
+	batch := make([]batchRequest, 0, b.size)
+	timer := time.NewTimer(b.maxWait)
+	timer.Stop()
+	for {
+		req, ok := <-b.requests
+		if !ok {
+			return
+		}
+		batch = append(batch[:0], req)
+		timer.Reset(b.maxWait)
+
+		closed := false
+	collect:
+		for len(batch) < b.full() {
+			select {
+			case req, ok := <-b.requests:
+				if !ok {
+					closed = true
+					break collect
+				}
+				batch = append(batch, req)
+			case <-b.wake:
+			case <-timer.C:
+				break collect
+			}
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+
+		b.run(batch)
+		if closed {
+			return
+		}
+	}
+}
+
+func (b *Batcher) run(batch []batchRequest) {
+	inputs := make([][]float32, len(batch))
+	for i, req := range batch {
+		inputs[i] = req.input
+	}
+	policies, values, err := b.inf.InferBatch(inputs)
+	if err == nil && (len(policies) != len(batch) || len(values) != len(batch)) {
+		err = errors.Errorf("Expected %d results from InferBatch. Got %d policies and %d values", len(batch), len(policies), len(values))
+	}
+	for i, req := range batch {
+		if err != nil {
+			req.ret <- batchResult{err: err}
+			continue
+		}
+		req.ret <- batchResult{policy: policies[i], value: values[i]}
+	}
+}
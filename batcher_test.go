@@ -0,0 +1,156 @@
+package agogo
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	dual "github.com/gorgonia/agogo/dualnet"
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/game/mnk"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingInferer is a BatchInferer that returns the first element of each input as the value.
+type countingInferer struct {
+	sync.Mutex
+	calls  int
+	sizes  []int
+	closed bool
+}
+
+func (c *countingInferer) InferBatch(inputs [][]float32) (policies [][]float32, values []float32, err error) {
+	c.Lock()
+	c.calls++
+	c.sizes = append(c.sizes, len(inputs))
+	c.Unlock()
+	for _, in := range inputs {
+		policies = append(policies, []float32{1})
+		values = append(values, in[0])
+	}
+	return
+}
+
+func (c *countingInferer) Close() error { c.closed = true; return nil }
+
+func TestBatcher(t *testing.T) {
+	assert := assert.New(t)
+	inf := new(countingInferer)
+	enc := func(g game.State) []float32 { return []float32{float32(g.MoveNumber())} }
+	b := NewBatcher(inf, enc, 4, time.Second)
+
+	// 8 concurrent requests should be evaluated in 2 full batches, well before the maximum wait time.
+	start := time.Now()
+	var wg sync.WaitGroup
+	values := make([]float32, 8)
+	for i := range values {
+		g := mnk.TicTacToe()
+		for j := 0; j < i; j++ {
+			g.Apply(game.PlayerMove{Player: game.Player(game.Black), Single: game.Single(j)})
+		}
+		wg.Add(1)
+		go func(i int, g game.State) {
+			defer wg.Done()
+			_, values[i] = b.Infer(g)
+		}(i, g)
+	}
+	wg.Wait()
+	assert.True(time.Since(start) < time.Second, "Full batches should not wait")
+	assert.Equal(2, inf.calls)
+	assert.Equal([]int{4, 4}, inf.sizes)
+	for i, v := range values {
+		assert.Equal(float32(i), v, "Each request should get its own result")
+	}
+
+	// a single request waits for the maximum wait time, and is then evaluated on its own.
+	b2 := NewBatcher(new(countingInferer), enc, 4, 10*time.Millisecond)
+	_, v := b2.Infer(mnk.TicTacToe())
+	assert.Equal(float32(0), v)
+
+	assert.NoError(b.Close())
+	assert.NoError(b2.Close())
+	assert.True(inf.closed)
+}
+
+func TestBatcher_AddSearches(t *testing.T) {
+	assert := assert.New(t)
+	inf := new(countingInferer)
+	enc := func(g game.State) []float32 { return []float32{float32(g.MoveNumber())} }
+	b := NewBatcher(inf, enc, 8, time.Minute)
+	defer b.Close()
+
+	// 3 searches in flight fill a batch as soon as they have all submitted a board
+	b.AddSearches(3)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.Infer(mnk.TicTacToe())
+		}()
+	}
+	wg.Wait()
+	assert.True(time.Since(start) < time.Minute/2, "The batch should not wait for boards that no search may submit")
+	assert.Equal([]int{3}, inf.sizes)
+
+	// once a search is over, the pending batch does not wait for it either
+	done := make(chan struct{})
+	go func() {
+		b.Infer(mnk.TicTacToe())
+		close(done)
+	}()
+	b.AddSearches(-2)
+	select {
+	case <-done:
+	case <-time.After(time.Minute / 2):
+		t.Fatal("The batch of the remaining search should be evaluated")
+	}
+	assert.Equal([]int{3, 1}, inf.sizes)
+}
+
+// failingInferer is a BatchInferer that fails to close.
+type failingInferer struct{ countingInferer }
+
+func (f *failingInferer) Close() error { return errors.New("close failed") }
+
+func TestAgent_SwitchToInference_BatcherError(t *testing.T) {
+	conf := tictactoeConf()
+	nn := dual.New(conf.NNConf)
+	if err := nn.Init(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	a := newAgent(nn)
+	a.Enc = encodeTicTacToe
+	a.batchWait = time.Millisecond
+	a.batcher = NewBatcher(new(failingInferer), a.Enc, 1, time.Millisecond)
+	if err := a.SwitchToInference(mnk.TicTacToe()); err == nil {
+		t.Fatal("Expected the error of closing the batcher")
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- a.SwitchToInference(mnk.TicTacToe()) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("%+v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected the agent to be unlocked after an error")
+	}
+	a.closeBatcher()
+}
+
+func TestConfig_batchSize(t *testing.T) {
+	assert := assert.New(t)
+	conf := tictactoeConf()
+	conf.NNConf.BatchSize = 256
+	conf.SelfPlayWorkers = 2
+	assert.Equal(2*runtime.NumCPU(), conf.batchSize(), "The batch should not be bigger than the number of searches in flight")
+	conf.MCTSConf.Deterministic = true
+	assert.Equal(2, conf.batchSize())
+	conf.NNConf.BatchSize = 1
+	assert.Equal(1, conf.batchSize())
+}
@@ -0,0 +1,155 @@
+package agogo
+
+import (
+	"encoding/gob"
+	"log"
+	"os"
+
+	dual "github.com/gorgonia/agogo/dualnet"
+	"github.com/gorgonia/agogo/mcts"
+	"github.com/pkg/errors"
+)
+
+// checkpoint is the header of a training checkpoint.
+//
+// In the checkpoint file, the header is followed by the neural networks of A and B, in that order.
+type checkpoint struct {
+	Epoch      int // the last completed epoch
+	GameNumber int
+	TrainSteps int // number of solver steps taken, for the learn rate schedule
+	Generation int // generation of the network of A
+
+	Replay     *ReplayBuffer
+	Statistics Statistics
+
+	NNConf          dual.Config
+	MCTSConf        mcts.Config
+	UpdateThreshold float32
+	Gate            SPRT
+	MaxExamples     int
+	Sampling        SamplingStrategy
+	ValidationSplit float64
+	Patience        int
+}
+
+// SaveCheckpoint saves the full training state into filename. The training may be resumed with Resume.
+//
+// The checkpoint is first written to a temporary file, which is then renamed to filename,
+// so a crash while writing will not clobber the previous checkpoint.
+//
+// The state of the solver (e.g. the moments of Adam) is not saved. It does not outlive an epoch anyway, as every epoch
+// trains with a fresh solver; only the number of steps taken, which drives the learn rate schedule, is kept.
+func (a *AZ) SaveCheckpoint(filename string) error {
+	tmp := filename + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	hdr := checkpoint{
+		Epoch:           a.epoch,
+		GameNumber:      a.gameNumber,
+		TrainSteps:      a.trainer.Step,
+		Generation:      a.generation,
+		Replay:          a.replay,
+		Statistics:      a.Statistics,
+		NNConf:          a.nnConf,
+		MCTSConf:        a.mctsConf,
+		UpdateThreshold: a.updateThreshold,
+		Gate:            a.gate,
+		MaxExamples:     a.maxExamples,
+		Sampling:        a.sampling,
+		ValidationSplit: a.validationSplit,
+		Patience:        a.trainer.Patience,
+	}
+
+	enc := gob.NewEncoder(f)
+	if err = enc.Encode(hdr); err != nil {
+		f.Close()
+		return errors.WithMessage(err, "Unable to encode checkpoint header")
+	}
+	if err = enc.Encode(a.A.NN); err != nil {
+		f.Close()
+		return errors.WithMessage(err, "Unable to encode A")
+	}
+	if err = enc.Encode(a.B.NN); err != nil {
+		f.Close()
+		return errors.WithMessage(err, "Unable to encode B")
+	}
+	if err = f.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(tmp, filename))
+}
+
+// LoadCheckpoint loads the full training state from a file written by SaveCheckpoint.
+func (a *AZ) LoadCheckpoint(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	var hdr checkpoint
+	dec := gob.NewDecoder(f)
+	if err = dec.Decode(&hdr); err != nil {
+		return errors.WithMessage(err, "Unable to decode checkpoint header")
+	}
+
+	A := dual.New(hdr.NNConf)
+	B := dual.New(hdr.NNConf)
+	if err = dec.Decode(A); err != nil {
+		return errors.WithMessage(err, "Unable to decode A")
+	}
+	if err = dec.Decode(B); err != nil {
+		return errors.WithMessage(err, "Unable to decode B")
+	}
+
+	a.epoch = hdr.Epoch
+	a.gameNumber = hdr.GameNumber
+	a.trainer.Step = hdr.TrainSteps
+	a.generation = hdr.Generation
+	if hdr.Replay != nil {
+		a.replay.Window = hdr.Replay.Window
+		a.replay.Generations = hdr.Replay.Generations
+	}
+	a.Statistics = hdr.Statistics
+	// gob does not transmit empty maps
+	if a.Statistics.Wins == nil {
+		a.Statistics.Wins = make(map[string][]float32)
+	}
+	if a.Statistics.Losses == nil {
+		a.Statistics.Losses = make(map[string][]float32)
+	}
+	if a.Statistics.Draws == nil {
+		a.Statistics.Draws = make(map[string][]float32)
+	}
+	a.nnConf = hdr.NNConf
+	a.mctsConf = hdr.MCTSConf
+	a.conf = hdr.MCTSConf
+	a.updateThreshold = hdr.UpdateThreshold
+	a.gate = hdr.Gate
+	a.maxExamples = hdr.MaxExamples
+	a.sampling = hdr.Sampling
+	a.validationSplit = hdr.ValidationSplit
+	a.trainer.Patience = hdr.Patience
+	a.A.NN = A
+	a.B.NN = B
+	a.A.MCTS = a.A.newMCTS(a.game, a.Arena.mctsConf())
+	a.B.MCTS = a.B.newMCTS(a.game, a.Arena.mctsConf())
+	a.bgen = a.generation + 1
+	a.caches = make(map[int]*mcts.EvalCache)
+	a.setCaches()
+	a.useDummy = false
+	return nil
+}
+
+// Resume loads the checkpoint in filename and continues learning from the epoch after the one it was saved at.
+// iters is the total number of epochs, including the ones that were completed before the checkpoint was made.
+func (a *AZ) Resume(filename string, iters, episodes, nniters, arenaGames int) error {
+	if err := a.LoadCheckpoint(filename); err != nil {
+		return err
+	}
+	log.Printf("Resuming from %v. Last completed epoch %d", filename, a.epoch)
+	return a.learn(a.epoch+1, iters, episodes, nniters, arenaGames)
+}
@@ -0,0 +1,126 @@
+package agogo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dual "github.com/gorgonia/agogo/dualnet"
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/game/mnk"
+	"github.com/gorgonia/agogo/mcts"
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeTicTacToe(a game.State) []float32 {
+	board := EncodeTwoPlayerBoard(a.Board(), nil)
+	playerLayer := make([]float32, len(a.Board()))
+	if a.ToMove() == game.Player(game.White) {
+		for i := range playerLayer {
+			playerLayer[i] = -1
+		}
+	} else {
+		for i := range playerLayer {
+			playerLayer[i] = 1
+		}
+	}
+	return append(board, playerLayer...)
+}
+
+func tictactoeConf() Config {
+	conf := Config{
+		Name:            "Tic Tac Toe",
+		NNConf:          dual.DefaultConf(3, 3, 10),
+		MCTSConf:        mcts.DefaultConfig(3),
+		UpdateThreshold: 0.52,
+		Encoder:         encodeTicTacToe,
+	}
+	conf.NNConf.BatchSize = 10
+	conf.NNConf.Features = 2
+	conf.NNConf.K = 3
+	conf.NNConf.SharedLayers = 1
+	return conf
+}
+
+func TestAZ_Checkpoint(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "agogo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "checkpoint")
+
+	conf := tictactoeConf()
+	conf.ReplayWindow = 2
+	conf.MCTSConf.Budget = 7
+	a := New(mnk.TicTacToe(), conf)
+	a.epoch = 3
+	a.gameNumber = 7
+	a.generation = 2
+	a.replay.Push([]Example{{Board: make([]float32, 18), Policy: make([]float32, 10), Value: 1}})
+	a.replay.Push([]Example{{Board: make([]float32, 18), Policy: []float32{1, 0, 0, 0, 0, 0, 0, 0, 0, 0}, Value: -1}})
+	a.Statistics.Creation = append(a.Statistics.Creation, "A")
+	a.Statistics.Wins["A"] = []float32{1, 2}
+	a.Statistics.Losses["A"] = []float32{2, 1}
+	a.Statistics.Draws["A"] = []float32{0, 1}
+	a.Statistics.rate(1, 2, 3, 1, 0)
+	a.Statistics.recordTraining(2, dual.Metrics{Iteration: 1, Batch: -1, Loss: 0.5})
+	if err := a.SaveCheckpoint(filename); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	b := New(mnk.TicTacToe(), tictactoeConf())
+	if err := b.LoadCheckpoint(filename); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	assert.Equal(3, b.epoch)
+	assert.Equal(7, b.gameNumber)
+	assert.Equal(2, b.generation)
+	assert.Equal(a.replay.Window, b.replay.Window)
+	assert.Equal(a.replay.Generations, b.replay.Generations, "The pending examples should be restored")
+	assert.Equal(a.Statistics, b.Statistics)
+	assert.Equal(a.nnConf, b.nnConf)
+	assert.Equal(int32(7), b.A.MCTS.Budget, "The search trees should use the restored MCTS config")
+	assert.Equal(int32(7), b.B.MCTS.Budget, "The search trees should use the restored MCTS config")
+	assert.False(b.useDummy)
+
+	amodel := a.A.NN.Model()
+	bmodel := b.A.NN.Model()
+	for i := range amodel {
+		assert.Equal(amodel[i].Value().Data(), bmodel[i].Value().Data(), "A's %v should be the same", amodel[i])
+	}
+	amodel = a.B.NN.Model()
+	bmodel = b.B.NN.Model()
+	for i := range amodel {
+		assert.Equal(amodel[i].Value().Data(), bmodel[i].Value().Data(), "B's %v should be the same", amodel[i])
+	}
+	assert.NotEqual(a.A.NN.Model()[0].Value().Data(), b.B.NN.Model()[0].Value().Data(), "A and B should not be mixed up")
+}
+
+func TestAZ_Checkpoint_noGames(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "agogo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "checkpoint")
+
+	// no arena games played yet, so the win rate maps are empty, but there are already training metrics
+	a := New(mnk.TicTacToe(), tictactoeConf())
+	a.Statistics.recordTraining(1, dual.Metrics{Iteration: 0, Batch: -1, Loss: 0.5})
+	if err := a.SaveCheckpoint(filename); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	b := New(mnk.TicTacToe(), tictactoeConf())
+	if err := b.LoadCheckpoint(filename); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	assert.Equal(a.Statistics.Training, b.Statistics.Training, "The training metrics should be restored")
+	assert.NotNil(b.Statistics.Wins)
+	assert.NotNil(b.Statistics.Losses)
+	assert.NotNil(b.Statistics.Draws)
+}
@@ -2,6 +2,8 @@ package agogo
 
 import (
 	"io"
+	"runtime"
+	"time"
 
 	dual "github.com/gorgonia/agogo/dualnet"
 	"github.com/gorgonia/agogo/game"
@@ -15,8 +17,47 @@ type Config struct {
 	Name            string
 	NNConf          dual.Config
 	MCTSConf        mcts.Config
-	UpdateThreshold float64
-	MaxExamples     int // maximum number of examples
+	UpdateThreshold float64 // fraction of the decisive arena games that the candidate must win to be accepted
+	MaxExamples     int     // maximum number of examples sampled from the replay buffer to train on
+	Checkpoint      string  // if set, a training checkpoint is written to this file at the end of every epoch
+
+	// Gate, if valid, replaces UpdateThreshold: the candidate is accepted or rejected by a sequential probability ratio test,
+	// and the arena stops as soon as the test is decided. The arenaGames argument to Learn is then the maximum number of arena games,
+	// and a candidate is rejected if the test is still undecided after them.
+	Gate SPRT
+
+	SelfPlayWorkers int // number of self play games played concurrently. Defaults to 1
+
+	// BatchWait enables batched inference when it is > 0: leaf evaluations from concurrent searches are evaluated together,
+	// in batches of up to NNConf.BatchSize boards, or of as many boards as there are searches in flight, if that is fewer.
+	// A batch waits at most BatchWait for more boards to arrive.
+	BatchWait time.Duration
+
+	// EvalCacheSize is the number of evaluations cached for every generation of network across searches and games,
+	// so that positions that are played again, such as openings, are not evaluated again. Agents that play with the same
+	// network share its cache, and an accepted network keeps the evaluations it made as a candidate. If 0, there is no cache.
+	EvalCacheSize int
+
+	// MaxMoves, if > 0, stops the games after this many moves. They are then adjudicated by score.
+	MaxMoves int
+
+	// ValueLabeller labels the value targets of the self play examples. If nil, it is OutcomeLabeller
+	ValueLabeller ValueLabeller
+
+	// replay buffer
+	ReplayWindow   int              // number of generations (epochs) of self play examples to keep. Defaults to 1
+	ReplaySampling SamplingStrategy // how training examples are sampled from the replay buffer
+
+	// ValidationSplit is the fraction of the sampled examples that is held out to validate the training of the candidate network.
+	// When there is validation data, the weights with the lowest validation loss are kept,
+	// and training stops early when the validation loss stops improving. The nniters argument to Learn is then the maximum number of passes.
+	ValidationSplit float64
+	Patience        int // number of passes without improvement of the validation loss before training stops. Defaults to 1
+
+	// Seed, if not 0, seeds every random number generator: the search trees, the initial weights of the networks,
+	// the choice of colours, and the sampling and shuffling of the examples. It overrides the seeds in MCTSConf and NNConf.
+	// Together with MCTSConf.Deterministic, the same seed plays the same games.
+	Seed int64
 
 	// extensions
 	Encoder       GameEncoder
@@ -24,6 +65,23 @@ type Config struct {
 	Augmenter     Augmenter
 }
 
+// batchSize returns the size of the batches of batched inference: NNConf.BatchSize, capped at the number of searches that
+// may wait on an agent's batcher at once. Every self play worker searches with one goroutine per CPU, or one if the search is deterministic.
+func (c Config) batchSize() int {
+	workers := c.SelfPlayWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	searchers := runtime.NumCPU()
+	if c.MCTSConf.Deterministic {
+		searchers = 1
+	}
+	if retVal := workers * searchers; retVal < c.NNConf.BatchSize {
+		return retVal
+	}
+	return c.NNConf.BatchSize
+}
+
 // GameEncoder encodes a game state as a slice of floats
 type GameEncoder func(a game.State) []float32
 
@@ -58,6 +116,12 @@ type Inferer interface {
 	io.Closer
 }
 
+// BatchInferer is anything that can infer a batch of inputs in one go.
+type BatchInferer interface {
+	InferBatch(inputs [][]float32) (policies [][]float32, values []float32, err error)
+	io.Closer
+}
+
 // ExecLogger is anything that can return the execution log.
 type ExecLogger interface {
 	ExecLog() string
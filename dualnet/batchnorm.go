@@ -0,0 +1,88 @@
+package dual
+
+import (
+	"reflect"
+	"unsafe"
+
+	"gorgonia.org/tensor"
+)
+
+// runningStats returns the running statistics of a batch norm op: the running mean, the running variance,
+// and the moving average factor they are scaled by. Gorgonia does not export them.
+//
+// It returns nil if the op does not keep them itself, as is the case of the CUDA batch norm.
+func runningStats(op batchNormOp) []*tensor.Dense {
+	v := reflect.ValueOf(op).Elem()
+	retVal := make([]*tensor.Dense, 0, 3)
+	for _, name := range []string{"mean", "variance", "ma"} {
+		f := v.FieldByName(name)
+		if !f.IsValid() || f.Type() != reflect.TypeOf((*tensor.Dense)(nil)) {
+			return nil
+		}
+		retVal = append(retVal, *(**tensor.Dense)(unsafe.Pointer(f.UnsafeAddr())))
+	}
+	return retVal
+}
+
+// setInference puts the batch norm ops in inference mode, where the running statistics are used instead of the statistics of the batch.
+//
+// The running statistics of Gorgonia are sums scaled by the moving average factor. In inference mode, they are multiplied by the factor
+// instead of divided, unless the factor is 1, so they are normalised to a factor of 1 first. Call setTraining to undo.
+func (d *Dual) setInference() {
+	for _, op := range d.ops {
+		op.SetTesting()
+		stats := runningStats(op)
+		if stats == nil {
+			continue
+		}
+		ma := stats[2].Data().([]float32)
+		if ma[0] > 0 {
+			for _, s := range stats[:2] {
+				data := s.Data().([]float32)
+				for i := range data {
+					data[i] /= ma[0]
+				}
+			}
+		}
+		ma[0] = 1
+	}
+}
+
+// setTraining puts the batch norm ops back in training mode, with the running statistics saved in stats by saveStats.
+func (d *Dual) setTraining(stats [][]float32) {
+	for _, op := range d.ops {
+		op.SetTraining() // this zeroes the running statistics
+	}
+	d.restoreStats(stats)
+}
+
+// saveStats copies the running statistics of the batch norm ops into buf, allocating it if needed.
+func (d *Dual) saveStats(buf [][]float32) [][]float32 {
+	if buf == nil {
+		buf = make([][]float32, 0, 3*len(d.ops))
+		for _, op := range d.ops {
+			for _, s := range runningStats(op) {
+				buf = append(buf, make([]float32, s.Len()))
+			}
+		}
+	}
+	var i int
+	for _, op := range d.ops {
+		for _, s := range runningStats(op) {
+			copy(buf[i], s.Data().([]float32))
+			i++
+		}
+	}
+	return buf
+}
+
+// restoreStats copies the running statistics in buf back into the batch norm ops.
+func (d *Dual) restoreStats(buf [][]float32) {
+	var i int
+	for _, op := range d.ops {
+		for _, s := range runningStats(op) {
+			copy(s.Data().([]float32), buf[i])
+			i++
+		}
+	}
+}
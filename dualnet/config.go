@@ -12,7 +12,18 @@ type Config struct {
 	Features      int // feature counts
 
 	ActionSpace int
-	FwdOnly     bool // is this a fwd only graph?
+	FwdOnly     bool  // is this a fwd only graph?
+	Seed        int64 // seed of the initial weights. If 0, they are seeded from the clock
+
+	// training
+	Solver       SolverType // the solver used to train the network
+	LearnRate    float64    // the base learn rate. Defaults to 0.1
+	Momentum     float64    // momentum of the MomentumSGD solver. Defaults to 0.9
+	Schedule     Schedule   // the learn rate schedule
+	WarmupSteps  int        // number of steps over which the learn rate is linearly increased to LearnRate
+	DecaySteps   int        // StepDecay: number of steps between decays. CosineDecay: number of steps to decay over
+	DecayRate    float64    // StepDecay: the learn rate is multiplied by this every DecaySteps. Defaults to 0.1
+	MinLearnRate float64    // CosineDecay: the final learn rate
 }
 
 func DefaultConf(m, n, actionSpace int) Config {
@@ -38,7 +49,11 @@ func (conf Config) IsValid() bool {
 		conf.FC > 1 &&
 		conf.BatchSize >= 1 &&
 		// conf.ActionSpace >= conf.Width*conf.Height &&
-		conf.Features > 0
+		conf.Features > 0 &&
+		conf.L2 >= 0 &&
+		conf.LearnRate >= 0 &&
+		conf.Solver >= 0 && conf.Solver < MAXSOLVERTYPE &&
+		conf.Schedule >= 0 && conf.Schedule < MAXSCHEDULE
 }
 
 func round(a int) int {
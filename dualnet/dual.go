@@ -3,6 +3,7 @@ package dual
 import (
 	"bytes"
 	"encoding/gob"
+	"math/rand"
 
 	G "gorgonia.org/gorgonia"
 	"gorgonia.org/tensor"
@@ -27,6 +28,9 @@ type Dual struct {
 	policyValue G.Value // policy predicted
 	value       G.Value // the actual value predicted
 	cost        G.Value // cost, for training recoring
+	policyCost  G.Value // policy cost, for training recording
+	valueCost   G.Value // value cost, for training recording
+	costs       G.Nodes // the nodes reading the costs. They are the roots of the forward pass of training
 }
 
 // New returns a new, uninitialized *Dual.
@@ -56,6 +60,9 @@ func (d *Dual) fwd(actionSpace int) (logits, valueOutput *G.Node) {
 	d.planes = G.NewTensor(d.g, Float, 4, G.WithShape(d.BatchSize, d.Features, d.Height, d.Width), G.WithName("Planes"))
 
 	var m maebe
+	if d.Seed != 0 {
+		m.r = rand.New(rand.NewSource(d.Seed))
+	}
 	initialOut, initalOp := m.res(d.planes, d.K, "Init")
 	d.ops = append(d.ops, initalOp)
 
@@ -122,7 +129,11 @@ func (d *Dual) bwd(actionSpace int, logits, valueOutput *G.Node) error {
 	if m.err != nil {
 		return m.err
 	}
-	G.Read(ccost, &d.cost)
+	d.costs = G.Nodes{
+		G.Read(ccost, &d.cost),
+		G.Read(pcost, &d.policyCost),
+		G.Read(vcost, &d.valueCost),
+	}
 
 	if _, err := G.Grad(ccost, d.Model()...); err != nil {
 		return err
@@ -175,6 +186,7 @@ func (d *Dual) reset() {
 
 	d.planes = nil
 	d.policyOutput = nil
+	d.costs = nil
 }
 
 func (d *Dual) GobEncode() (retVal []byte, err error) {
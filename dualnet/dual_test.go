@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
+	"math/rand"
 	"os"
 	"runtime"
 	"testing"
@@ -50,6 +51,7 @@ func TestSanity(t *testing.T) {
 	costFile, _ := os.OpenFile("cost.csv", os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0644)
 
 	defer costFile.Close()
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 	for i := 0; i < 250; i++ {
 		start := time.Now()
 		if i > 0 {
@@ -65,7 +67,7 @@ func TestSanity(t *testing.T) {
 		fmt.Fprintf(costFile, "%v, %v, %v, %v\n", d.cost, fwd.Sub(start), step.Sub(fwd), time.Since(start))
 		// fmt.Fprintf(costFile, "%v, %v, %v, %v\n", d.cost, 0.0, 0.0, 0.0)
 		m.Reset()
-		shuffleBatch(f, π, v)
+		shuffleBatch(r, f, π, v)
 		time.Sleep(1)
 	}
 	// costFile.WriteString("Cost, Fowward Time, SGD Time, Total Time")
@@ -160,6 +162,71 @@ func TestInferencer_ExecLog(t *testing.T) {
 	}
 }
 
+func TestInferencer_InferBatch(t *testing.T) {
+	boardSize := 3
+	actionSpace := boardSize*boardSize + 1
+	conf := DefaultConf(boardSize, boardSize, actionSpace)
+	conf.BatchSize = 4
+	conf.Features = 1
+	d := &Dual{Config: conf}
+	if err := d.Init(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	inferer, err := InferBatched(d, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inferer.Close()
+
+	assert := assert.New(t)
+	assert.Equal(conf.BatchSize, inferer.BatchSize())
+
+	boards := [][]float32{
+		{-1, 0, 1, -1, 1, 0, 0, 0, 0},
+		{0, 0, 0, 0, 1, 0, 0, 0, 0},
+		{1, -1, 1, -1, 1, -1, 0, 0, 0},
+	}
+	policies, values, err := inferer.InferBatch(boards)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(len(boards), len(policies))
+	assert.Equal(len(boards), len(values))
+
+	// every board is inferred the same, whatever its slot in the batch
+	single, err := InferBatched(d, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer single.Close()
+	for i, board := range boards {
+		policy, value, err := single.Infer(board)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.InDeltaSlice(policy, policies[i], 1e-5, "Policy %d should be the same as the one inferred alone", i)
+		assert.InDelta(value, values[i], 1e-5, "Value %d should be the same as the one inferred alone", i)
+	}
+
+	// the returned policies are copies, so they must not be changed by subsequent inferences.
+	before := append([]float32(nil), policies[0]...)
+	if _, _, err = inferer.InferBatch(boards[1:]); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(before, policies[0])
+
+	if _, _, err = inferer.InferBatch(make([][]float32, 5)); err == nil {
+		t.Error("Expected an error when inferring more boards than the batch size")
+	}
+
+	small, err := InferBatched(d, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer small.Close()
+	assert.Equal(2, small.BatchSize())
+}
+
 func TestShuffleBatch(t *testing.T) {
 	Xs := tensor.New(tensor.WithShape(5, 1, 3, 2), tensor.WithBacking(G.Uniform(150, 152)(tensor.Float32, 5, 1, 3, 2)))
 	pis := tensor.New(tensor.WithShape(5, 6), tensor.WithBacking(G.Uniform(0, 1)(tensor.Float32, 5, 6)))
@@ -169,7 +236,8 @@ func TestShuffleBatch(t *testing.T) {
 	originalPis := pis.Clone().(*tensor.Dense)
 	originalVs := vs.Clone().(*tensor.Dense)
 
-	if err := shuffleBatch(Xs, pis, vs); err != nil {
+	r := rand.New(rand.NewSource(1337))
+	if err := shuffleBatch(r, Xs, pis, vs); err != nil {
 		t.Errorf("err")
 	}
 	assert := assert.New(t)
@@ -2,6 +2,8 @@ package dual
 
 import (
 	"fmt"
+	"math"
+	"math/rand"
 
 	"github.com/pkg/errors"
 	G "gorgonia.org/gorgonia"
@@ -11,6 +13,7 @@ import (
 
 type maebe struct {
 	err error
+	r   *rand.Rand // if not nil, the weights are initialized from r
 }
 
 type batchNormOp interface {
@@ -36,7 +39,7 @@ func (m *maebe) conv(input *G.Node, filterCount, size int, name string) (retVal
 	}
 	featureCount := input.Shape()[1]
 	padding := findPadding(input.Shape()[2], input.Shape()[3], size, size)
-	filter := G.NewTensor(input.Graph(), Float, 4, G.WithShape(filterCount, featureCount, size, size), G.WithName("Filter"+name), G.WithInit(G.GlorotU(1.0)))
+	filter := G.NewTensor(input.Graph(), Float, 4, G.WithShape(filterCount, featureCount, size, size), G.WithName("Filter"+name), G.WithInit(m.glorot(1.0, true)))
 
 	// assume well behaved images
 	if retVal, m.err = nnops.Conv2d(input, filter, []int{size, size}, padding, []int{1, 1}, []int{1, 1}); m.err != nil {
@@ -51,7 +54,13 @@ func (m *maebe) batchnorm(input *G.Node) (retVal *G.Node, retOp batchNormOp) {
 	}
 	// note: the scale and biases will still be created
 	// and they will still be backpropagated
-	if retVal, _, _, retOp, m.err = nnops.BatchNorm(input, nil, nil, 0.997, 1e-5); m.err != nil {
+	var scale, bias *G.Node
+	if m.r != nil {
+		// same as the scale and biases that gorgonia creates, but seeded
+		scale = G.NewTensor(input.Graph(), Float, input.Dims(), G.WithShape(input.Shape().Clone()...), G.WithName(input.Name()+"_γ"), G.WithInit(m.glorot(1.0, false)))
+		bias = G.NewTensor(input.Graph(), Float, input.Dims(), G.WithShape(input.Shape().Clone()...), G.WithName(input.Name()+"_β"), G.WithInit(m.glorot(1.0, false)))
+	}
+	if retVal, _, _, retOp, m.err = nnops.BatchNorm(input, scale, bias, 0.997, 1e-5); m.err != nil {
 		m.err = errors.WithStack(m.err)
 	}
 	return
@@ -77,12 +86,45 @@ func (m *maebe) linear(input *G.Node, units int, name string) *G.Node {
 		return nil
 	}
 	// figure out size
-	w := G.NewTensor(input.Graph(), Float, 2, G.WithShape(input.Shape()[1], units), G.WithInit(G.GlorotN(1.0)), G.WithName(name+"_w"))
+	w := G.NewTensor(input.Graph(), Float, 2, G.WithShape(input.Shape()[1], units), G.WithInit(m.glorot(1.0, false)), G.WithName(name+"_w"))
 	xw := m.do(func() (*G.Node, error) { return G.Mul(input, w) })
 	b := G.NewTensor(xw.Graph(), Float, xw.Shape().Dims(), G.WithShape(xw.Shape().Clone()...), G.WithName(name+"_b"), G.WithInit(G.Zeroes()))
 	return m.do(func() (*G.Node, error) { return G.Add(xw, b) })
 }
 
+// glorot returns the Glorot et al. initialization of the weights, either uniform or normal.
+// Without a random number generator, gorgonia's initialization is used, which is seeded from the clock.
+func (m *maebe) glorot(gain float64, uniform bool) G.InitWFn {
+	if m.r == nil {
+		if uniform {
+			return G.GlorotU(gain)
+		}
+		return G.GlorotN(gain)
+	}
+	return func(dt tensor.Dtype, s ...int) interface{} {
+		if dt != tensor.Float32 {
+			panic(fmt.Sprintf("Seeded initialization of %v is not supported", dt))
+		}
+		n1, n2, fieldSize := 1, s[0], 1
+		if len(s) > 1 {
+			n1, n2 = s[0], s[1]
+			for _, v := range s[2:] {
+				fieldSize *= v
+			}
+		}
+		stdev := gain * math.Sqrt(2.0/float64((n1+n2)*fieldSize))
+		retVal := make([]float32, tensor.Shape(s).TotalSize())
+		for i := range retVal {
+			if uniform {
+				retVal[i] = float32((2*m.r.Float64() - 1) * math.Sqrt(3.0) * stdev)
+			} else {
+				retVal[i] = float32(m.r.NormFloat64() * stdev)
+			}
+		}
+		return retVal
+	}
+}
+
 func (m *maebe) rectify(input *G.Node) (retVal *G.Node) {
 	if m.err != nil {
 		return nil
@@ -3,7 +3,9 @@ package dual
 import (
 	"bytes"
 	"log"
+	"math"
 	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -12,15 +14,62 @@ import (
 	"gorgonia.org/tensor/native"
 )
 
-// Train is a basic trainer.
+// Train is a basic trainer. The learn rate schedule starts from the first step.
 func Train(d *Dual, Xs, policies, values *tensor.Dense, batches, iterations int) error {
+	var t Trainer
+	return t.Train(d, Xs, policies, values, batches, iterations)
+}
+
+// Trainer trains a *Dual with the solver and learn rate schedule set in the Config of the *Dual.
+//
+// Unlike Train, a Trainer keeps count of the steps taken across calls to Train,
+// so the learn rate schedule continues where it left off when a new network is trained.
+type Trainer struct {
+	Step int   // number of solver steps taken so far
+	Seed int64 // seed of the shuffling of the examples between passes. If 0, it is seeded from the clock
+	r    *rand.Rand
+
+	// Report, if set, is called with the metrics of every batch, and with the average metrics at the end of every pass through the batches.
+	Report func(Metrics)
+
+	// Validation data. If ValBatches > 0, the policy and value loss on the validation data are measured after every pass
+	// through the training batches. The weights with the lowest validation loss are kept,
+	// and training stops early when the validation loss has not improved for Patience passes (at least 1).
+	ValXs, ValPolicies, ValValues *tensor.Dense
+	ValBatches                    int
+	Patience                      int
+}
+
+// Train trains d for the given number of iterations over the batches.
+// If there is validation data, iterations is the maximum number of passes, and training may stop earlier.
+func (t *Trainer) Train(d *Dual, Xs, policies, values *tensor.Dense, batches, iterations int) error {
 	m := G.NewTapeMachine(d.g, G.BindDualValues(d.Model()...))
 	defer m.Close()
 	model := G.NodesToValueGrads(d.Model())
-	solver := G.NewVanillaSolver(G.WithLearnRate(0.1))
+	solver := d.Config.solver()
 	var s slicer
+	if t.r == nil {
+		seed := t.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		t.r = rand.New(rand.NewSource(seed))
+	}
+
+	patience := t.Patience
+	if patience < 1 {
+		patience = 1
+	}
+	var fwd G.VM // forward only machine for the validation
+	if t.ValBatches > 0 {
+		fwd = G.NewTapeMachine(d.g.SubgraphRoots(d.costs...))
+		defer fwd.Close()
+	}
+	var best [][]float32 // the weights and batch norm statistics with the lowest validation loss
+	bestLoss := float32(math.Inf(1))
+	bestPass, lastPass := -1, -1
 	for i := 0; i < iterations; i++ {
-		// var cost float32
+		pass := Metrics{Iteration: i, Batch: -1}
 		for bat := 0; bat < batches; bat++ {
 			batchStart := bat * d.Config.BatchSize
 			batchEnd := batchStart + d.Config.BatchSize
@@ -35,27 +84,121 @@ func Train(d *Dual, Xs, policies, values *tensor.Dense, batches, iterations int)
 			if err := m.RunAll(); err != nil {
 				return err
 			}
-			// cost = d.cost.Data().(float32)
+			lr := d.Config.learnRate(t.Step)
+			if t.Report != nil {
+				bm, err := d.measure(d.Model())
+				if err != nil {
+					return err
+				}
+				bm.Iteration, bm.Batch, bm.Step, bm.LearnRate = i, bat, t.Step, lr
+				t.Report(bm)
+				pass.add(bm)
+			}
+			G.WithLearnRate(lr)(solver)
 			if err := solver.Step(model); err != nil {
 				return err
 			}
+			t.Step++
 			m.Reset()
 			tensor.ReturnTensor(Xs2)
 			tensor.ReturnTensor(π)
 			tensor.ReturnTensor(v)
 		}
-		if err := shuffleBatch(Xs, policies, values); err != nil {
+		if err := shuffleBatch(t.r, Xs, policies, values); err != nil {
 			return err
 		}
-		// TODO: add a channel to send training  cost data down
-		// log.Printf("%d\t%v", i, cost/float32(batches))
+		lastPass = i
+		if t.ValBatches > 0 {
+			val, err := t.validate(d, fwd)
+			if err != nil {
+				return err
+			}
+			pass.ValPolicyLoss, pass.ValValueLoss, pass.ValLoss = val.PolicyLoss, val.ValueLoss, val.Loss
+			if val.Loss < bestLoss {
+				bestLoss, bestPass = val.Loss, i
+				best = snapshot(d, best)
+			}
+		}
+		if t.Report != nil {
+			pass.scale(batches)
+			t.Report(pass)
+		}
+		if t.ValBatches > 0 && i-bestPass >= patience {
+			break
+		}
+	}
+	if best != nil && bestPass != lastPass {
+		restore(d, best)
 	}
 	return nil
 }
 
+// validate measures the average policy and value loss of d on the validation data.
+// m must be a forward only machine, so that no gradients are computed. The batch norm ops are in inference mode,
+// so the validation data does not change their running statistics.
+func (t *Trainer) validate(d *Dual, m G.VM) (retVal Metrics, err error) {
+	stats := d.saveStats(nil)
+	d.setInference()
+	defer d.setTraining(stats)
+
+	var s slicer
+	for bat := 0; bat < t.ValBatches; bat++ {
+		batchStart := bat * d.Config.BatchSize
+		batchEnd := batchStart + d.Config.BatchSize
+
+		Xs := s.Slice(t.ValXs, sli(batchStart, batchEnd))
+		π := s.Slice(t.ValPolicies, sli(batchStart, batchEnd))
+		v := s.Slice(t.ValValues, sli(batchStart, batchEnd))
+
+		G.Let(d.planes, Xs)
+		G.Let(d.Π, π)
+		G.Let(d.V, v)
+		if err = m.RunAll(); err != nil {
+			return retVal, err
+		}
+		retVal.PolicyLoss += d.policyCost.Data().(float32)
+		retVal.ValueLoss += d.valueCost.Data().(float32)
+		m.Reset()
+		tensor.ReturnTensor(Xs)
+		tensor.ReturnTensor(π)
+		tensor.ReturnTensor(v)
+	}
+	retVal.scale(t.ValBatches)
+	retVal.Loss = retVal.PolicyLoss + retVal.ValueLoss
+	return retVal, nil
+}
+
+// snapshot copies the weights of the model, followed by the running statistics of the batch norm ops, into buf, allocating it if needed.
+func snapshot(d *Dual, buf [][]float32) [][]float32 {
+	model := d.Model()
+	if buf == nil {
+		buf = make([][]float32, len(model))
+	}
+	for i, n := range model {
+		w := n.Value().Data().([]float32)
+		if buf[i] == nil {
+			buf[i] = make([]float32, len(w))
+		}
+		copy(buf[i], w)
+	}
+	if len(buf) == len(model) {
+		return append(buf, d.saveStats(nil)...)
+	}
+	d.saveStats(buf[len(model):])
+	return buf
+}
+
+// restore copies the weights and the batch norm statistics in buf back into the model.
+func restore(d *Dual, buf [][]float32) {
+	model := d.Model()
+	for i, n := range model {
+		copy(n.Value().Data().([]float32), buf[i])
+	}
+	d.restoreStats(buf[len(model):])
+}
+
 // shuffleBatch shuffles the batches.
-func shuffleBatch(Xs, π, v *tensor.Dense) (err error) {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+func shuffleBatch(r *rand.Rand, Xs, π, v *tensor.Dense) (err error) {
 	oriXs := Xs.Shape().Clone()
 	oriPis := π.Shape().Clone()
 
@@ -123,11 +266,24 @@ type Inferencer struct {
 
 // Infer takes a trained *Dual, and creates a interence data structure such that it'd be easy to infer
 func Infer(d *Dual, actionSpace int, toLog bool) (*Inferencer, error) {
+	return newInferencer(d, actionSpace, toLog)
+}
+
+// InferBatched takes a trained *Dual, and creates an inference data structure that is able to infer
+// up to batchSize boards in one forward pass (see InferBatch). If batchSize is not positive, it is d.BatchSize.
+func InferBatched(d *Dual, batchSize int, toLog bool) (*Inferencer, error) {
+	if batchSize <= 0 {
+		batchSize = d.BatchSize
+	}
+	return newInferencer(d, batchSize, toLog)
+}
+
+func newInferencer(d *Dual, batchSize int, toLog bool) (*Inferencer, error) {
 	conf := d.Config
 	conf.FwdOnly = true
-	conf.BatchSize = actionSpace
+	conf.BatchSize = batchSize
 	newShape := d.planes.Shape().Clone()
-	newShape[0] = actionSpace
+	newShape[0] = batchSize
 	retVal := &Inferencer{
 		d:     New(conf),
 		input: tensor.New(tensor.WithShape(newShape...), tensor.Of(Float)),
@@ -142,7 +298,16 @@ func Infer(d *Dual, actionSpace int, toLog bool) (*Inferencer, error) {
 	for i, n := range d.Model() {
 		original := n.Value().Data().([]float32)
 		cloned := infModel[i].Value().Data().([]float32)
-		copy(cloned, original)
+		if !isBatchNormParam(n) {
+			copy(cloned, original)
+			continue
+		}
+		// the batch norm scales and biases have a row for every slot of the batch.
+		// Every board is inferred with the first row, whatever its slot, as Infer does.
+		rowSize := len(original) / n.Shape()[0]
+		for j := 0; j < len(cloned); j += rowSize {
+			copy(cloned[j:j+rowSize], original[:rowSize])
+		}
 	}
 
 	retVal.buf = new(bytes.Buffer)
@@ -161,6 +326,11 @@ func Infer(d *Dual, actionSpace int, toLog bool) (*Inferencer, error) {
 	return retVal, nil
 }
 
+// isBatchNormParam returns true if n is the scale or the bias of a batch norm.
+func isBatchNormParam(n *G.Node) bool {
+	return strings.HasSuffix(n.Name(), "_γ") || strings.HasSuffix(n.Name(), "_β")
+}
+
 // Dual implements Dualer
 func (m *Inferencer) Dual() *Dual { return m.d }
 
@@ -189,6 +359,46 @@ func (m *Inferencer) Infer(board []float32) (policy []float32, value float32, er
 	return policy[:m.d.ActionSpace], value, nil
 }
 
+// InferBatch runs inference on up to BatchSize() boards in one forward pass.
+// The returned policies are copies, and may be retained by the caller.
+func (m *Inferencer) InferBatch(boards [][]float32) (policies [][]float32, values []float32, err error) {
+	if len(boards) > m.BatchSize() {
+		return nil, nil, errors.Errorf("Cannot infer %d boards in a batch of %d", len(boards), m.BatchSize())
+	}
+	m.buf.Reset()
+	for _, op := range m.d.ops {
+		op.Reset()
+	}
+
+	m.input.Zero()
+	data := m.input.Data().([]float32)
+	rowSize := len(data) / m.BatchSize()
+	for i, board := range boards {
+		copy(data[i*rowSize:(i+1)*rowSize], board)
+	}
+
+	m.m.Reset()
+	G.Let(m.d.planes, m.input)
+	if err = m.m.RunAll(); err != nil {
+		return nil, nil, err
+	}
+
+	actionSpace := m.d.ActionSpace
+	policy := m.d.policyValue.Data().([]float32)
+	value := m.d.value.Data().([]float32)
+	policies = make([][]float32, len(boards))
+	values = make([]float32, len(boards))
+	for i := range boards {
+		policies[i] = make([]float32, actionSpace)
+		copy(policies[i], policy[i*actionSpace:(i+1)*actionSpace])
+		values[i] = value[i]
+	}
+	return policies, values, nil
+}
+
+// BatchSize returns the maximum number of boards that can be inferred in one forward pass.
+func (m *Inferencer) BatchSize() int { return m.input.Shape()[0] }
+
 // ExecLog returns the execution log. If Infer was called with toLog = false, then it will return an empty string
 func (m *Inferencer) ExecLog() string { return m.buf.String() }
 
@@ -0,0 +1,78 @@
+package dual
+
+import (
+	"github.com/chewxy/math32"
+	G "gorgonia.org/gorgonia"
+)
+
+// Metrics are the training metrics of a batch, or the average metrics of a pass through all the batches.
+type Metrics struct {
+	Iteration int     // the pass through the batches
+	Batch     int     // the batch in the pass. It is -1 if the metrics are the average of the pass
+	Step      int     // the solver step. For the average of a pass, this is the last step of the pass
+	LearnRate float64 // the learn rate used for the step
+
+	PolicyLoss float32 // cross entropy of the policy
+	ValueLoss  float32 // mean squared error of the value
+	L2         float32 // L2 regularization term: L2/2 * sum of the squared weights
+	Loss       float32 // combined loss: PolicyLoss + ValueLoss + L2
+	GradNorm   float32 // L2 norm of the gradients of all the weights
+
+	// validation losses. They are only measured at the end of a pass, when the Trainer has validation data
+	ValPolicyLoss float32
+	ValValueLoss  float32
+	ValLoss       float32 // ValPolicyLoss + ValValueLoss
+}
+
+// IsPass returns true if the metrics are the average of a pass through all the batches.
+func (m Metrics) IsPass() bool { return m.Batch < 0 }
+
+// measure reads the metrics of the batch that was just run. It must be called before the solver steps.
+func (d *Dual) measure(model G.Nodes) (retVal Metrics, err error) {
+	retVal.PolicyLoss = d.policyCost.Data().(float32)
+	retVal.ValueLoss = d.valueCost.Data().(float32)
+
+	var sumSq, gradSq float32
+	for _, n := range model {
+		if d.L2 > 0 {
+			for _, w := range n.Value().Data().([]float32) {
+				sumSq += w * w
+			}
+		}
+		var grad G.Value
+		if grad, err = n.Grad(); err != nil {
+			return retVal, err
+		}
+		for _, g := range grad.Data().([]float32) {
+			gradSq += g * g
+		}
+	}
+	retVal.L2 = float32(d.L2) / 2 * sumSq
+	retVal.Loss = retVal.PolicyLoss + retVal.ValueLoss + retVal.L2
+	retVal.GradNorm = math32.Sqrt(gradSq)
+	return retVal, nil
+}
+
+// add accumulates the losses of other into m. It is used to compute the average of a pass.
+func (m *Metrics) add(other Metrics) {
+	m.PolicyLoss += other.PolicyLoss
+	m.ValueLoss += other.ValueLoss
+	m.L2 += other.L2
+	m.Loss += other.Loss
+	m.GradNorm += other.GradNorm
+	m.Step = other.Step
+	m.LearnRate = other.LearnRate
+}
+
+// scale divides the losses by n.
+func (m *Metrics) scale(n int) {
+	if n == 0 {
+		return
+	}
+	f := float32(n)
+	m.PolicyLoss /= f
+	m.ValueLoss /= f
+	m.L2 /= f
+	m.Loss /= f
+	m.GradNorm /= f
+}
@@ -0,0 +1,119 @@
+package dual
+
+import (
+	"math"
+
+	G "gorgonia.org/gorgonia"
+)
+
+const (
+	defaultLearnRate = 0.1
+	defaultMomentum  = 0.9
+	defaultDecayRate = 0.1
+)
+
+// SolverType is the type of the gradient descent solver used to train the network.
+type SolverType int
+
+const (
+	VanillaSGD  SolverType = iota // stochastic gradient descent
+	MomentumSGD                   // stochastic gradient descent with momentum
+	Adam
+	RMSProp
+	MAXSOLVERTYPE
+)
+
+func (s SolverType) String() string {
+	switch s {
+	case VanillaSGD:
+		return "VanillaSGD"
+	case MomentumSGD:
+		return "MomentumSGD"
+	case Adam:
+		return "Adam"
+	case RMSProp:
+		return "RMSProp"
+	}
+	return "UNKNOWN SOLVER"
+}
+
+// Schedule is a learn rate schedule.
+type Schedule int
+
+const (
+	ConstantRate Schedule = iota // the learn rate stays the same
+	StepDecay                    // the learn rate is multiplied by DecayRate every DecaySteps steps
+	CosineDecay                  // the learn rate follows a half cosine from LearnRate to MinLearnRate over DecaySteps steps
+	MAXSCHEDULE
+)
+
+func (s Schedule) String() string {
+	switch s {
+	case ConstantRate:
+		return "ConstantRate"
+	case StepDecay:
+		return "StepDecay"
+	case CosineDecay:
+		return "CosineDecay"
+	}
+	return "UNKNOWN SCHEDULE"
+}
+
+// solver creates a new solver as configured.
+func (conf Config) solver() G.Solver {
+	opts := []G.SolverOpt{G.WithLearnRate(conf.learnRate(0))}
+	if conf.L2 > 0 {
+		opts = append(opts, G.WithL2Reg(conf.L2))
+	}
+	switch conf.Solver {
+	case MomentumSGD:
+		momentum := conf.Momentum
+		if momentum == 0 {
+			momentum = defaultMomentum
+		}
+		opts = append(opts, G.WithMomentum(momentum))
+		return G.NewMomentum(opts...)
+	case Adam:
+		return G.NewAdamSolver(opts...)
+	case RMSProp:
+		return G.NewRMSPropSolver(opts...)
+	}
+	return G.NewVanillaSolver(opts...)
+}
+
+// learnRate returns the learn rate at the given training step.
+//
+// The warmup, if any, comes before the schedule: during the first WarmupSteps steps the learn rate increases linearly to LearnRate,
+// and the schedule starts counting steps after the warmup.
+func (conf Config) learnRate(step int) float64 {
+	base := conf.LearnRate
+	if base == 0 {
+		base = defaultLearnRate
+	}
+	if step < conf.WarmupSteps {
+		return base * float64(step+1) / float64(conf.WarmupSteps)
+	}
+	step -= conf.WarmupSteps
+
+	switch conf.Schedule {
+	case StepDecay:
+		if conf.DecaySteps <= 0 {
+			return base
+		}
+		rate := conf.DecayRate
+		if rate == 0 {
+			rate = defaultDecayRate
+		}
+		return base * math.Pow(rate, float64(step/conf.DecaySteps))
+	case CosineDecay:
+		if conf.DecaySteps <= 0 {
+			return base
+		}
+		if step > conf.DecaySteps {
+			step = conf.DecaySteps
+		}
+		cos := 0.5 * (1 + math.Cos(math.Pi*float64(step)/float64(conf.DecaySteps)))
+		return conf.MinLearnRate + (base-conf.MinLearnRate)*cos
+	}
+	return base
+}
@@ -0,0 +1,182 @@
+package dual
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	G "gorgonia.org/gorgonia"
+	"gorgonia.org/tensor"
+)
+
+func TestConfig_learnRate(t *testing.T) {
+	assert := assert.New(t)
+	var conf Config
+	assert.Equal(defaultLearnRate, conf.learnRate(0), "Default learn rate")
+	assert.Equal(defaultLearnRate, conf.learnRate(1000), "Default learn rate is constant")
+
+	conf = Config{LearnRate: 1, WarmupSteps: 4}
+	assert.Equal(0.25, conf.learnRate(0))
+	assert.Equal(0.5, conf.learnRate(1))
+	assert.Equal(1.0, conf.learnRate(3))
+	assert.Equal(1.0, conf.learnRate(100))
+
+	conf = Config{LearnRate: 1, Schedule: StepDecay, DecaySteps: 10, DecayRate: 0.5}
+	assert.Equal(1.0, conf.learnRate(9))
+	assert.Equal(0.5, conf.learnRate(10))
+	assert.Equal(0.25, conf.learnRate(25))
+
+	conf = Config{LearnRate: 1, Schedule: CosineDecay, DecaySteps: 10, MinLearnRate: 0.1, WarmupSteps: 2}
+	assert.Equal(1.0, conf.learnRate(2), "The schedule starts after the warmup")
+	assert.InDelta(0.55, conf.learnRate(7), 1e-9, "Halfway through the cosine")
+	assert.InDelta(0.1, conf.learnRate(12), 1e-9)
+	assert.InDelta(0.1, conf.learnRate(1000), 1e-9, "Stays at the minimum")
+}
+
+func TestTrainer(t *testing.T) {
+	for s := SolverType(0); s < MAXSOLVERTYPE; s++ {
+		boardSize := 3
+		conf := DefaultConf(boardSize, boardSize, boardSize*boardSize+1)
+		conf.BatchSize = 2
+		conf.Features = 1
+		conf.K = 2
+		conf.SharedLayers = 1
+		conf.Solver = s
+		conf.L2 = 1e-4
+		conf.LearnRate = 0.01
+		if !conf.IsValid() {
+			t.Fatalf("%v: Expected config to be valid", s)
+		}
+		d := New(conf)
+		if err := d.Init(); err != nil {
+			t.Fatalf("%v: %+v", s, err)
+		}
+
+		examples := 4
+		Xs := tensor.New(tensor.WithShape(examples, 1, boardSize, boardSize), tensor.WithBacking(G.Uniform(-1, 1)(tensor.Float32, examples, 1, boardSize, boardSize)))
+		policies := tensor.New(tensor.WithShape(examples, boardSize*boardSize+1), tensor.WithBacking(G.Uniform(0, 1)(tensor.Float32, examples, boardSize*boardSize+1)))
+		values := tensor.New(tensor.WithShape(examples), tensor.WithBacking(G.Uniform(-1, 1)(tensor.Float32, examples)))
+
+		var reports []Metrics
+		trainer := Trainer{Step: 5, Report: func(m Metrics) { reports = append(reports, m) }}
+		if err := trainer.Train(d, Xs, policies, values, 2, 3); err != nil {
+			t.Fatalf("%v: %+v", s, err)
+		}
+		assert.Equal(t, 5+2*3, trainer.Step, "%v: Steps should continue from where they left off", s)
+
+		// every pass reports its 2 batches, followed by the average of the pass
+		assert.Len(t, reports, 3*(2+1), "%v", s)
+		for i, m := range reports {
+			assert.Equal(t, i/3, m.Iteration, "%v", s)
+			assert.Equal(t, i%3 == 2, m.IsPass(), "%v", s)
+			assert.True(t, m.L2 > 0, "%v: L2 term should be reported", s)
+			assert.True(t, m.GradNorm > 0, "%v: gradient norm should be reported", s)
+			assert.InDelta(t, m.PolicyLoss+m.ValueLoss+m.L2, m.Loss, 1e-4, "%v", s)
+		}
+		last := reports[len(reports)-1]
+		assert.Equal(t, 5+2*3-1, last.Step, "%v: a pass reports its last step", s)
+		assert.Equal(t, conf.learnRate(last.Step), last.LearnRate, "%v", s)
+	}
+}
+
+func TestTrainer_EarlyStopping(t *testing.T) {
+	boardSize := 3
+	actionSpace := boardSize*boardSize + 1
+	conf := DefaultConf(boardSize, boardSize, actionSpace)
+	conf.BatchSize = 2
+	conf.Features = 1
+	conf.K = 2
+	conf.SharedLayers = 1
+	conf.Solver = Adam
+	conf.LearnRate = 0.05
+	d := New(conf)
+	if err := d.Init(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	data := func(examples int) (Xs, policies, values *tensor.Dense) {
+		Xs = tensor.New(tensor.WithShape(examples, 1, boardSize, boardSize), tensor.WithBacking(G.Uniform(-1, 1)(tensor.Float32, examples, 1, boardSize, boardSize)))
+		policies = tensor.New(tensor.WithShape(examples, actionSpace), tensor.WithBacking(G.Uniform(0, 1)(tensor.Float32, examples, actionSpace)))
+		values = tensor.New(tensor.WithShape(examples), tensor.WithBacking(G.Uniform(-1, 1)(tensor.Float32, examples)))
+		return
+	}
+	Xs, policies, values := data(4)
+
+	// the validation data is unrelated to the training data, so training overfits and the validation loss stops improving
+	var passes []Metrics
+	trainer := Trainer{Patience: 2, Report: func(m Metrics) {
+		if m.IsPass() {
+			passes = append(passes, m)
+		}
+	}}
+	trainer.ValXs, trainer.ValPolicies, trainer.ValValues = data(4)
+	trainer.ValBatches = 2
+
+	iterations := 200
+	if err := trainer.Train(d, Xs, policies, values, 2, iterations); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if len(passes) >= iterations {
+		t.Fatalf("Expected training to stop early")
+	}
+	best := 0
+	for i, m := range passes {
+		assert.NotZero(t, m.ValLoss, "Validation loss should be reported for pass %d", i)
+		if m.ValLoss < passes[best].ValLoss {
+			best = i
+		}
+	}
+	assert.Equal(t, len(passes)-1-trainer.Patience, best, "Training should stop Patience passes after the best pass")
+
+	// the best weights and batch norm statistics should have been restored
+	m := G.NewTapeMachine(d.g.SubgraphRoots(d.costs...))
+	defer m.Close()
+	val, err := trainer.validate(d, m)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	assert.InDelta(t, passes[best].ValLoss, val.Loss, 1e-4)
+}
+
+func TestTrainer_validate(t *testing.T) {
+	boardSize := 3
+	actionSpace := boardSize*boardSize + 1
+	conf := DefaultConf(boardSize, boardSize, actionSpace)
+	conf.BatchSize = 2
+	conf.Features = 1
+	conf.K = 2
+	conf.SharedLayers = 1
+	d := New(conf)
+	if err := d.Init(); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	examples := 4
+	Xs := tensor.New(tensor.WithShape(examples, 1, boardSize, boardSize), tensor.WithBacking(G.Uniform(-1, 1)(tensor.Float32, examples, 1, boardSize, boardSize)))
+	policies := tensor.New(tensor.WithShape(examples, actionSpace), tensor.WithBacking(G.Uniform(0, 1)(tensor.Float32, examples, actionSpace)))
+	values := tensor.New(tensor.WithShape(examples), tensor.WithBacking(G.Uniform(-1, 1)(tensor.Float32, examples)))
+	if err := Train(d, Xs, policies, values, 2, 2); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	trainer := Trainer{ValBatches: 2}
+	trainer.ValXs = tensor.New(tensor.WithShape(examples, 1, boardSize, boardSize), tensor.WithBacking(G.Uniform(-1, 1)(tensor.Float32, examples, 1, boardSize, boardSize)))
+	trainer.ValPolicies, trainer.ValValues = policies, values
+
+	stats := d.saveStats(nil)
+	weights := snapshot(d, nil)
+	m := G.NewTapeMachine(d.g.SubgraphRoots(d.costs...))
+	defer m.Close()
+	val, err := trainer.validate(d, m)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	assert.NotZero(t, val.Loss)
+	assert.Equal(t, stats, d.saveStats(nil), "The validation data should not change the batch norm statistics")
+	assert.Equal(t, weights, snapshot(d, nil), "The validation should not change the weights")
+
+	// validating again gives the same losses
+	again, err := trainer.validate(d, m)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	assert.Equal(t, val, again)
+}
@@ -0,0 +1,108 @@
+package agogo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+)
+
+// z95 is the z-score of a 95% confidence interval
+const z95 = 1.959964
+
+// Rating is the Elo rating of an accepted network generation.
+//
+// Generation 0 is the initial network, and is rated 0. Every other generation is rated from the arena games it played
+// against the generation it replaced, so its rating is relative to generation 0.
+type Rating struct {
+	Generation int
+	Epoch      int // epoch in which the generation was accepted. It is -1 for generation 0
+
+	Elo       float64
+	Variance  float64 // variance of Elo, accumulated over all the generations before
+	Low, High float64 // 95% confidence interval of Elo
+
+	// results of the arena games against the previous generation, from the point of view of this generation
+	Wins, Losses, Draws float32
+}
+
+// generationName is the name of a generation, as used in the statistics.
+func generationName(gen int) string { return fmt.Sprintf("gen%d", gen) }
+
+// eloDiff returns the Elo difference implied by the score of a player against an opponent, along with its standard error.
+//
+// A score of 0 or 1 would imply an infinite difference, so the score is clamped to half a game away from them.
+func eloDiff(wins, losses, draws float64) (diff, stderr float64) {
+	n := wins + losses + draws
+	if n == 0 {
+		return 0, 0
+	}
+	s := (wins + draws/2) / n
+	lo, hi := 1/(2*n), 1-1/(2*n)
+	s = math.Max(lo, math.Min(hi, s))
+
+	diff = -400 * math.Log10(1/s-1)
+
+	// variance of the score of a single game, and then the standard error of the mean score
+	variance := (wins*(1-s)*(1-s) + losses*s*s + draws*(0.5-s)*(0.5-s)) / n
+	sderr := math.Sqrt(variance / n)
+
+	// delta method: dElo/ds = 400 / (ln(10) * s * (1-s))
+	stderr = sderr * 400 / (math.Ln10 * s * (1 - s))
+	return diff, stderr
+}
+
+// rate rates a newly accepted generation from its arena results against the latest rated generation.
+func (s *Statistics) rate(gen, epoch int, wins, losses, draws float32) Rating {
+	if len(s.Ratings) == 0 {
+		s.Ratings = append(s.Ratings, Rating{Generation: 0, Epoch: -1})
+	}
+	prev := s.Ratings[len(s.Ratings)-1]
+	diff, stderr := eloDiff(float64(wins), float64(losses), float64(draws))
+
+	r := Rating{
+		Generation: gen,
+		Epoch:      epoch,
+		Elo:        prev.Elo + diff,
+		Variance:   prev.Variance + stderr*stderr,
+		Wins:       wins,
+		Losses:     losses,
+		Draws:      draws,
+	}
+	ci := z95 * math.Sqrt(r.Variance)
+	r.Low, r.High = r.Elo-ci, r.Elo+ci
+	s.Ratings = append(s.Ratings, r)
+	return r
+}
+
+// DumpRatings dumps the Elo ratings of the accepted generations in filename using a CSV format.
+func (s *Statistics) DumpRatings(filename string) error {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	if err := w.Write([]string{"generation", "epoch", "elo", "low", "high", "wins", "losses", "draws"}); err != nil {
+		return err
+	}
+	f64 := func(a float64) string { return strconv.FormatFloat(a, 'f', 1, 64) }
+	for _, r := range s.Ratings {
+		record := []string{
+			strconv.Itoa(r.Generation),
+			strconv.Itoa(r.Epoch),
+			f64(r.Elo),
+			f64(r.Low),
+			f64(r.High),
+			strconv.FormatFloat(float64(r.Wins), 'f', -1, 32),
+			strconv.FormatFloat(float64(r.Losses), 'f', -1, 32),
+			strconv.FormatFloat(float64(r.Draws), 'f', -1, 32),
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
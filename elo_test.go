@@ -0,0 +1,45 @@
+package agogo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEloDiff(t *testing.T) {
+	assert := assert.New(t)
+
+	diff, stderr := eloDiff(5, 5, 10)
+	assert.Equal(0.0, diff, "An even score is no difference")
+	assert.True(stderr > 0)
+
+	diff, _ = eloDiff(3, 1, 0)
+	assert.InDelta(190.8, diff, 0.1, "A score of 75% is about 191 Elo")
+	diff2, _ := eloDiff(1, 3, 0)
+	assert.InDelta(-diff, diff2, 1e-9, "The difference should be symmetric")
+
+	diff, stderr = eloDiff(10, 0, 0)
+	assert.False(math.IsInf(diff, 0), "A perfect score should not be an infinite difference")
+	assert.True(diff > 0)
+	assert.True(stderr > 0)
+
+	_, se10 := eloDiff(6, 4, 0)
+	_, se100 := eloDiff(60, 40, 0)
+	assert.True(se100 < se10, "More games should narrow the error")
+}
+
+func TestStatistics_rate(t *testing.T) {
+	assert := assert.New(t)
+	s := makeStatistics()
+	r1 := s.rate(1, 0, 3, 1, 0)
+	r2 := s.rate(2, 4, 3, 1, 0)
+
+	assert.Equal(3, len(s.Ratings), "Generation 0 should be rated too")
+	assert.Equal(0.0, s.Ratings[0].Elo)
+	assert.InDelta(2*r1.Elo, r2.Elo, 1e-9, "Ratings should accumulate over the generations")
+	assert.InDelta(2*r1.Variance, r2.Variance, 1e-9)
+	assert.True(r2.Low < r2.Elo && r2.Elo < r2.High)
+	assert.True(r2.High-r2.Low > r1.High-r1.Low, "The confidence interval should widen over the generations")
+	assert.Equal(4, r2.Epoch)
+}
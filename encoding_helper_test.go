@@ -16,7 +16,8 @@ func TestRotateBoard(t *testing.T) {
 	// ⎢ X · · · O ⎥
 
 	m, n := 5, 5
-	board := []game.Colour{
+	None, Black, White := float32(game.None), float32(game.Black), float32(game.White)
+	board := []float32{
 		White, None, None, None, Black,
 		None, White, None, Black, None,
 		None, None, None, None, None,
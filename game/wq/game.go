@@ -80,10 +80,14 @@ func (g *Game) Check(m game.PlayerMove) bool {
 
 func (g *Game) Apply(m game.PlayerMove) game.State {
 	newState := g.Clone().(*Game)
-	// TODO : check for passes etc
-	captures, _ := newState.board.Apply(m)
-
-	newState.captures[m.Player-1] += captures
+	// TODO : check for resignations etc
+	if m.Single.IsPass() {
+		newState.passes++
+	} else {
+		captures, _ := newState.board.Apply(m)
+		newState.captures[m.Player-1] += captures
+		newState.passes = 0
+	}
 	newState.nextToMove = Opponent(m.Player)
 	newState.history = append(newState.history, m)
 	newState.histPtr++
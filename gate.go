@@ -0,0 +1,28 @@
+package agogo
+
+import "log"
+
+// gated returns true if the sequential probability ratio test of the candidate (B) is decided, and the arena may stop.
+func (a *AZ) gated() bool {
+	if !a.gate.IsValid() {
+		return false
+	}
+	return a.gate.Test(float64(a.B.Wins), float64(a.B.Loss), float64(a.B.Draw)).Decision != SPRTContinue
+}
+
+// accept decides whether the candidate (B) replaces A, based on the results of the arena.
+//
+// With a valid gate, the candidate is accepted only if the test accepts H1. Otherwise the candidate must win more than
+// updateThreshold of the decisive games. Draws are not decisive, so a candidate that only drew is rejected.
+func (a *AZ) accept() bool {
+	if a.gate.IsValid() {
+		res := a.gate.Test(float64(a.B.Wins), float64(a.B.Loss), float64(a.B.Draw))
+		log.Printf("SPRT: LLR %.2f (%.2f, %.2f) %v", res.LLR, res.Lower, res.Upper, res.Decision)
+		return res.Decision == SPRTAcceptH1
+	}
+	decisive := a.B.Wins + a.B.Loss
+	if decisive == 0 {
+		return false
+	}
+	return a.B.Wins/decisive > a.updateThreshold
+}
@@ -0,0 +1,39 @@
+package agogo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAZ_accept(t *testing.T) {
+	assert := assert.New(t)
+	a := &AZ{updateThreshold: 0.55}
+	a.A, a.B = new(Agent), new(Agent)
+
+	a.B.Draw = 10
+	assert.False(a.gated(), "Without a gate, the arena plays all its games")
+	assert.False(a.accept(), "A candidate that only drew should be rejected")
+
+	a.B.Wins, a.B.Loss = 6, 4
+	assert.True(a.accept())
+	a.B.Wins, a.B.Loss = 5, 5
+	assert.False(a.accept())
+
+	a.gate = SPRT{Elo0: 0, Elo1: 50, Alpha: 0.05, Beta: 0.05}
+	a.B.Wins, a.B.Loss, a.B.Draw = 6, 4, 0
+	assert.False(a.gated(), "The test should not be decided after 10 games")
+	assert.False(a.accept(), "An undecided candidate should be rejected")
+
+	a.B.Wins, a.B.Loss, a.B.Draw = 60, 10, 30
+	assert.True(a.gated())
+	assert.True(a.accept())
+
+	a.B.Wins, a.B.Loss, a.B.Draw = 10, 60, 30
+	assert.True(a.gated())
+	assert.False(a.accept())
+
+	a.B.Wins, a.B.Loss, a.B.Draw = 0, 0, 100
+	assert.True(a.gated(), "Many draws should be evidence against an improvement")
+	assert.False(a.accept())
+}
@@ -0,0 +1,180 @@
+package agogo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/gorgonia/agogo/game"
+	wq "github.com/gorgonia/agogo/game/wq"
+	"github.com/gorgonia/agogo/mcts"
+	"github.com/pkg/errors"
+)
+
+// GTPPlayer is an external engine, such as GNU Go, that is run as a subprocess and spoken to in the Go Text Protocol.
+//
+// The engine is told every move of the game, and asked for its own moves with genmove. It can only play on square boards.
+type GTPPlayer struct {
+	name   string
+	cmd    *exec.Cmd
+	in     io.WriteCloser
+	out    *bufio.Reader
+	board  *wq.GTPBoard // converts between points and GTP vertices
+	colour game.Player
+}
+
+// NewGTPPlayer starts the engine run by cmd. If name is empty, the engine is asked for its name.
+func NewGTPPlayer(name string, cmd *exec.Cmd) (*GTPPlayer, error) {
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, errors.Wrapf(err, "Unable to start GTP engine %v", cmd.Path)
+	}
+	retVal := &GTPPlayer{
+		name: name,
+		cmd:  cmd,
+		in:   in,
+		out:  bufio.NewReader(out),
+	}
+	if _, err = retVal.Send("protocol_version"); err != nil {
+		retVal.cmd.Process.Kill()
+		return nil, err
+	}
+	if retVal.name == "" {
+		if retVal.name, err = retVal.Send("name"); err != nil {
+			retVal.cmd.Process.Kill()
+			return nil, err
+		}
+	}
+	return retVal, nil
+}
+
+// Send sends a command to the engine and returns its response. A failure response is returned as an error.
+func (p *GTPPlayer) Send(command string) (string, error) {
+	if _, err := fmt.Fprintf(p.in, "%s\n", command); err != nil {
+		return "", errors.Wrapf(err, "Unable to send %q to %v", command, p.name)
+	}
+	var lines []string
+	for {
+		line, err := p.out.ReadString('\n')
+		if err != nil {
+			return "", errors.Wrapf(err, "Unable to read the response of %v to %q", p.name, command)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if len(lines) == 0 {
+				continue // stray empty line
+			}
+			break
+		}
+		lines = append(lines, line)
+	}
+	resp := strings.Join(lines, "\n")
+	status, resp := resp[0], strings.TrimSpace(resp[1:])
+	switch status {
+	case '=':
+		return resp, nil
+	case '?':
+		return "", errors.Errorf("%v failed to run %q: %v", p.name, command, resp)
+	}
+	return "", errors.Errorf("%v gave a malformed response to %q: %q", p.name, command, resp)
+}
+
+func (p *GTPPlayer) Name() string { return p.name }
+
+// Start sets up the board of the engine for a new game of g.
+func (p *GTPPlayer) Start(g game.State, colour game.Player) error {
+	m, n := g.BoardSize()
+	if m != n {
+		return errors.Errorf("GTP only supports square boards. Got %dx%d", m, n)
+	}
+	p.colour = colour
+	p.board = wq.NewGTPBoard(n, float64(g.AdditionalScore()))
+	for _, command := range []string{
+		fmt.Sprintf("boardsize %d", n),
+		"clear_board",
+		fmt.Sprintf("komi %v", g.AdditionalScore()),
+	} {
+		if _, err := p.Send(command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Move asks the engine to generate a move. A move that is illegal in g, as played by a buggy engine
+// or one that is out of sync with the game, is an error.
+func (p *GTPPlayer) Move(g game.State) (game.Single, error) {
+	player := g.ToMove()
+	resp, err := p.Send("genmove " + gtpColour(player))
+	if err != nil {
+		return mcts.Pass, err
+	}
+	move, err := p.single(resp)
+	if err != nil {
+		return mcts.Pass, err
+	}
+	if !move.IsPass() && !move.IsResignation() && !g.Check(game.PlayerMove{Player: player, Single: move}) {
+		return mcts.Pass, errors.Errorf("%v played an illegal move %q", p.name, resp)
+	}
+	return move, nil
+}
+
+// Played tells the engine the moves of its opponent. Its own moves were played by genmove.
+func (p *GTPPlayer) Played(m game.PlayerMove) error {
+	if m.Player == p.colour || m.IsResignation() {
+		return nil
+	}
+	_, err := p.Send(fmt.Sprintf("play %v %v", gtpColour(m.Player), p.vertex(m.Single)))
+	return err
+}
+
+func (p *GTPPlayer) End(rec GameRecord) error { return nil }
+
+// Close quits the engine, and waits for it to exit.
+func (p *GTPPlayer) Close() error {
+	if _, err := p.Send("quit"); err != nil {
+		p.cmd.Process.Kill()
+	}
+	p.in.Close()
+	return errors.WithStack(p.cmd.Wait())
+}
+
+// vertex returns the GTP vertex of a move.
+func (p *GTPPlayer) vertex(s game.Single) string {
+	if s.IsPass() {
+		return "pass"
+	}
+	size := p.board.Size
+	return p.board.StringFromXY(int(s)%size+1, int(s)/size+1)
+}
+
+// single returns the move of a GTP vertex.
+func (p *GTPPlayer) single(vertex string) (game.Single, error) {
+	switch strings.ToLower(vertex) {
+	case "pass":
+		return mcts.Pass, nil
+	case "resign":
+		return mcts.Resign, nil
+	}
+	x, y, err := p.board.XYFromString(vertex)
+	if err != nil {
+		return mcts.Pass, errors.Wrapf(err, "%v played an invalid vertex %q", p.name, vertex)
+	}
+	return game.Single((y-1)*p.board.Size + x - 1), nil
+}
+
+func gtpColour(p game.Player) string {
+	if p == game.Player(game.White) {
+		return "w"
+	}
+	return "b"
+}
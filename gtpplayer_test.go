@@ -0,0 +1,87 @@
+package agogo
+
+import (
+	"log"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/game/mnk"
+	wq "github.com/gorgonia/agogo/game/wq"
+	"github.com/gorgonia/agogo/mcts"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHelperProcess is not a test. It is the stub GTP engine run by the tests of GTPPlayer, which plays the first legal point.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	wq.StartGTP(func(colour int, board *wq.GTPBoard) string {
+		moves := board.AllLegalMoves(colour)
+		if len(moves) == 0 {
+			return "pass"
+		}
+		return board.StringFromPoint(moves[0])
+	}, "stub", "1.0")
+}
+
+func stubEngine(t *testing.T) *GTPPlayer {
+	cmd := exec.Command(os.Args[0], "-test.run=TestHelperProcess", "--")
+	cmd.Env = append(os.Environ(), "GO_WANT_HELPER_PROCESS=1")
+	p, err := NewGTPPlayer("", cmd)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	return p
+}
+
+func TestGTPPlayer(t *testing.T) {
+	assert := assert.New(t)
+	p := stubEngine(t)
+	assert.Equal("stub", p.Name(), "The name should be asked to the engine")
+
+	// wq does not score games yet, but GTP engines play on any square board
+	ar := &Arena{game: mnk.TicTacToe(), r: mcts.NewRand(1337), maxMoves: 4}
+	ar.logger = log.New(&ar.buf, "", log.Ltime)
+	rec, err := ar.Match(NewRandomPlayer("random", 1337), p, nil)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	assert.Equal(MoveLimit, rec.Termination)
+	assert.Len(rec.Moves, 4)
+
+	// the stub plays the first legal point in its column major order: A3, A2, A1, B3...
+	var engine []game.Single
+	for _, m := range rec.Moves {
+		if m.Player == game.Player(game.White) {
+			engine = append(engine, m.Single)
+		}
+	}
+	for _, m := range engine {
+		assert.Equal(0, int(m)%3, "The engine should play in the first column: %v", rec.Moves)
+	}
+	assert.Equal("A3", p.vertex(0))
+	assert.Equal("C1", p.vertex(8))
+	assert.Equal("pass", p.vertex(-1))
+	s, err := p.single("b1")
+	assert.NoError(err)
+	assert.Equal(game.Single(7), s)
+	_, err = p.single("d1")
+	assert.Error(err, "D1 is off a 3x3 board")
+
+	// an engine that is out of sync with the game plays illegal moves: it thinks A3 is empty
+	g := mnk.TicTacToe()
+	assert.NoError(p.Start(g, game.Player(game.White)))
+	g.Apply(game.PlayerMove{Player: game.Player(game.Black), Single: 0})
+	_, err = p.Move(g)
+	if assert.Error(err, "An illegal move should be an error") {
+		assert.Contains(err.Error(), "stub")
+		assert.Contains(err.Error(), "A3")
+	}
+
+	_, err = p.Send("undo_everything")
+	assert.Error(err, "A failure response should be an error")
+	assert.NoError(p.Close())
+}
@@ -2,10 +2,14 @@ package gtp
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/gorgonia/agogo/game"
+	wq "github.com/gorgonia/agogo/game/wq"
+	"github.com/gorgonia/agogo/mcts"
 	"github.com/pkg/errors"
 )
 
@@ -105,6 +109,8 @@ func play(e *Engine, args []string) (string, error) {
 	return "", errors.New("NYI")
 }
 
+// genmove generates a move for the given colour, and plays it. If the colour has a clock, the move is generated
+// in the time that its TimeManager allocates, and the time taken is spent from the clock.
 func genmove(e *Engine, args []string) (string, error) {
 	if len(args) == 0 {
 		return "", errors.New("Not enough arguments for \"genmove\"")
@@ -112,7 +118,111 @@ func genmove(e *Engine, args []string) (string, error) {
 	if e.Generate == nil {
 		return "", errors.New("Unable to generate moves. No generator found")
 	}
-	return "", errors.New("NYI")
+	p, err := parseColour(args[0])
+	if err != nil {
+		return "", err
+	}
+
+	ctx := context.Background()
+	tm := e.Time[p]
+	if tm != nil {
+		if d := tm.Allocate(e.g.MoveNumber()); d > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+	}
+	start := time.Now()
+	m := e.Generate(ctx, e.g, p)
+	if tm != nil {
+		tm.Spend(time.Since(start))
+	}
+
+	switch {
+	case m.IsResignation():
+		return "resign", nil
+	case m.IsPass():
+		e.g = e.g.Apply(m)
+		return "pass", nil
+	}
+	if !e.g.Check(m) {
+		return "", errors.Errorf("Generated an illegal move %v", m)
+	}
+	e.g = e.g.Apply(m)
+	size, _ := e.g.BoardSize()
+	return wq.NewGTPBoard(size, 0).StringFromXY(int(m.Single)%size+1, int(m.Single)/size+1), nil
+}
+
+// timeSettings sets the time control of both players. The arguments are the main time, the byoyomi time and the byoyomi stones,
+// with the times in seconds.
+func timeSettings(e *Engine, args []string) (string, error) {
+	if len(args) < 3 {
+		return "", errors.New("Not enough arguments for \"time_settings\"")
+	}
+	var ints [3]int
+	for i := range ints {
+		var err error
+		if ints[i], err = strconv.Atoi(args[i]); err != nil || ints[i] < 0 {
+			return "", errors.Errorf("Unable to parse argument %d of time_settings: %q", i+1, args[i])
+		}
+	}
+	tc := mcts.TimeControl{
+		MainTime:      time.Duration(ints[0]) * time.Second,
+		ByoyomiTime:   time.Duration(ints[1]) * time.Second,
+		ByoyomiStones: ints[2],
+	}
+	var expected int
+	if e.g != nil {
+		expected = e.g.ActionSpace() / 2
+	}
+	e.Time = make(map[game.Player]*mcts.TimeManager)
+	for _, p := range []game.Player{game.Player(game.Black), game.Player(game.White)} {
+		tm := mcts.NewTimeManager(tc)
+		tm.ExpectedMoves = expected
+		e.Time[p] = tm
+	}
+	return "", nil
+}
+
+// timeLeft sets the clock of a player. The arguments are the colour, the time left in seconds, and the stones left to play in
+// the byoyomi period (0 in the main time).
+func timeLeft(e *Engine, args []string) (string, error) {
+	if len(args) < 3 {
+		return "", errors.New("Not enough arguments for \"time_left\"")
+	}
+	p, err := parseColour(args[0])
+	if err != nil {
+		return "", err
+	}
+	left, err := strconv.Atoi(args[1])
+	if err != nil {
+		return "", errors.WithMessage(err, "Unable to parse the time of time_left")
+	}
+	stones, err := strconv.Atoi(args[2])
+	if err != nil {
+		return "", errors.WithMessage(err, "Unable to parse the stones of time_left")
+	}
+	tm, ok := e.Time[p]
+	if !ok {
+		// no time_settings. The time left is all the main time there is
+		tm = mcts.NewTimeManager(mcts.TimeControl{MainTime: time.Duration(left) * time.Second})
+		if e.Time == nil {
+			e.Time = make(map[game.Player]*mcts.TimeManager)
+		}
+		e.Time[p] = tm
+	}
+	tm.SetTimeLeft(time.Duration(left)*time.Second, stones)
+	return "", nil
+}
+
+func parseColour(a string) (game.Player, error) {
+	switch a {
+	case "b", "black":
+		return game.Player(game.Black), nil
+	case "w", "white":
+		return game.Player(game.White), nil
+	}
+	return game.Player(game.None), errors.Errorf("Unknown colour %q", a)
 }
 
 func StandardLib() map[string]Command {
@@ -131,5 +241,7 @@ func StandardLib() map[string]Command {
 		"komi":          stdlib2(komi),
 		"play":          stdlib2(play),
 		"genmove":       stdlib2(genmove),
+		"time_settings": stdlib2(timeSettings),
+		"time_left":     stdlib2(timeLeft),
 	}
 }
@@ -1,11 +1,13 @@
 package gtp
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/mcts"
 	"github.com/pkg/errors"
 )
 
@@ -29,6 +31,10 @@ var known_commands = []string{
 	"play",
 	"genmove",
 	"undo",
+
+	// time
+	"time_settings",
+	"time_left",
 }
 
 type Engine struct {
@@ -39,7 +45,11 @@ type Engine struct {
 	ch  chan string
 	ret chan string
 
-	Generate      func(g game.State) game.PlayerMove
+	// Generate generates a move of the given player, without playing it. It should stop searching when ctx is done:
+	// if the player has a clock in Time, the deadline of ctx is the time allocated to the move, so that it may be passed to MCTS.SearchContext.
+	// genmove spends the time taken from the clock, so the tree must not have a time manager of its own (see MCTS.SetTimeManager).
+	Generate      func(ctx context.Context, g game.State, p game.Player) game.PlayerMove
+	Time          map[game.Player]*mcts.TimeManager // clocks of the players, set by time_settings and time_left
 	New           func(m, n int) game.State
 	name, version string
 }
@@ -1,7 +1,14 @@
 package gtp
 
 import (
+	"context"
+	"fmt"
 	"testing"
+	"time"
+
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/game/mnk"
+	wq "github.com/gorgonia/agogo/game/wq"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -29,3 +36,82 @@ func Test_General(t *testing.T) {
 	assert.Equal("? Unknown command \"completelyunheardofcommand\"\n\n", x)
 
 }
+
+func Test_Time(t *testing.T) {
+	assert := assert.New(t)
+	e := New(nil, "xx", "1", nil)
+	ch, ret := e.Start()
+
+	ch <- "1 time_settings 300 30 5"
+	assert.Equal("= 1 \n\n", <-ret)
+	black := e.Time[game.Player(game.Black)]
+	if assert.NotNil(black) {
+		assert.Equal(300*time.Second, black.MainTime)
+		assert.Equal(30*time.Second, black.ByoyomiTime)
+		assert.Equal(5, black.ByoyomiStones)
+	}
+
+	ch <- "2 time_left white 20 3"
+	assert.Equal("= 2 \n\n", <-ret)
+	left, stones := e.Time[game.Player(game.White)].TimeLeft()
+	assert.Equal(20*time.Second, left)
+	assert.Equal(3, stones)
+
+	ch <- "3 time_left purple 20 0"
+	assert.Equal("? 3 Unknown colour \"purple\"\n\n", <-ret)
+
+	ch <- "4 time_settings 300"
+	assert.Equal("? 4 Not enough arguments for \"time_settings\"\n\n", <-ret)
+}
+
+func Test_GenmoveTime(t *testing.T) {
+	assert := assert.New(t)
+	e := New(mnk.TicTacToe(), "xx", "1", nil)
+	// the generator thinks until it runs out of time, and plays the first empty point
+	e.Generate = func(ctx context.Context, g game.State, p game.Player) game.PlayerMove {
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+		}
+		for i, c := range g.Board() {
+			if c == game.None {
+				return game.PlayerMove{Player: p, Single: game.Single(i)}
+			}
+		}
+		return game.PlayerMove{Player: p, Single: -1}
+	}
+	ch, ret := e.Start()
+	genmove := func(id int, colour string) time.Duration {
+		start := time.Now()
+		ch <- fmt.Sprintf("%d genmove %v", id, colour)
+		<-ret
+		return time.Since(start)
+	}
+
+	ch <- "1 time_left black 2 0"
+	assert.Equal("= 1 \n\n", <-ret)
+	short := genmove(2, "black")
+
+	ch <- "3 time_left white 20 0"
+	assert.Equal("= 3 \n\n", <-ret)
+	long := genmove(4, "white")
+	assert.True(long > 3*short, "More time left should be more time spent. Spent %v with 2s and %v with 20s", short, long)
+	assert.True(long < 5*time.Second, "The move should be generated in the time allocated. Spent %v", long)
+
+	left, _ := e.Time[game.Player(game.White)].TimeLeft()
+	assert.True(left < 20*time.Second, "The time spent should be taken from the clock")
+	assert.Equal(game.Colour(game.White), e.State().Board()[1], "The generated move should be played")
+}
+
+func Test_GenmovePass(t *testing.T) {
+	assert := assert.New(t)
+	e := New(wq.New(9, 0, 7.5), "xx", "1", nil)
+	e.Generate = func(ctx context.Context, g game.State, p game.Player) game.PlayerMove {
+		return game.PlayerMove{Player: p, Single: -1}
+	}
+	ch, ret := e.Start()
+	ch <- "1 genmove black"
+	assert.Equal("= 1 pass\n\n", <-ret)
+	assert.Equal(1, e.State().MoveNumber(), "The generated pass should be played")
+	assert.Equal(game.Player(game.White), e.State().ToMove())
+}
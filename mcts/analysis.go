@@ -0,0 +1,266 @@
+package mcts
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/chewxy/math32"
+	"github.com/gorgonia/agogo/game"
+)
+
+// earlyStopInterval is how often a parallel search checks whether the best move is decided.
+const earlyStopInterval = 5 * time.Millisecond
+
+// z95 is the z-score of a 95% confidence interval
+const z95 = 1.959964
+
+// SearchResult is a snapshot of the root of a search. It can be marshalled to JSON.
+type SearchResult struct {
+	Player   game.Player   `json:"player"`   // player to move at the root
+	Move     game.Single   `json:"move"`     // move chosen by the last search. While searching, it is the most visited move so far
+	Playouts int32         `json:"playouts"` // iterations of the search so far
+	Visits   uint32        `json:"visits"`   // visits of the root
+	Winrate  float32       `json:"winrate"`  // expected score of Player
+	Proof    Proof         `json:"proof"`    // proven result of the root, with Config.Solver
+	PV       []game.Single `json:"pv"`       // principal variation: the most visited line of play, up to PVDepth moves
+	Children []ChildResult `json:"children"` // children of the root, most visited first
+}
+
+// ChildResult is the statistics of a move from the root.
+type ChildResult struct {
+	Move   game.Single `json:"move"`
+	Visits uint32      `json:"visits"`
+	Q      float32     `json:"q"`     // mean value of Move, for the player to move at the root
+	Prior  float32     `json:"prior"` // probability of Move given by the neural network
+	LCB    float32     `json:"lcb"`   // lower bound of the 95% confidence interval of Q. It is 0 for moves visited less than twice
+	Proof  Proof       `json:"proof"` // proven result of Move, with Config.Solver
+}
+
+// lcb is the lower confidence bound of a mean value q over a number of visits, using the normal approximation of the binomial.
+func lcb(q float32, visits uint32) float32 {
+	if visits < 2 {
+		return 0
+	}
+	retVal := q - z95*math32.Sqrt(q*(1-q)/float32(visits))
+	if retVal < 0 {
+		return 0
+	}
+	return retVal
+}
+
+// Policy returns the policy target of the search: the visits of the children, normalised, and raised to the power of 1/temperature
+// to sharpen (temperature < 1) or flatten (temperature > 1) the distribution. A temperature of 0 is 1.
+//
+// The policy has actionSpace+1 entries, the last one being Pass, like the policy of the neural network.
+// It is nil if no child has been visited.
+func (r SearchResult) Policy(actionSpace int, temperature float32) []float32 {
+	if temperature <= 0 {
+		temperature = 1
+	}
+	var max uint32
+	for _, c := range r.Children {
+		if c.Visits > max {
+			max = c.Visits
+		}
+	}
+	if max == 0 {
+		return nil
+	}
+
+	retVal := make([]float32, actionSpace+1)
+	var sum float64
+	for _, c := range r.Children {
+		if c.Visits == 0 {
+			continue
+		}
+		i := int(c.Move)
+		if c.Move.IsPass() {
+			i = actionSpace
+		}
+		if i < 0 || i > actionSpace {
+			continue
+		}
+		// normalised by the most visited child first, so that low temperatures do not overflow
+		p := math.Pow(float64(c.Visits)/float64(max), 1/float64(temperature))
+		retVal[i] = float32(p)
+		sum += p
+	}
+	for i := range retVal {
+		retVal[i] = float32(float64(retVal[i]) / sum)
+	}
+	return retVal
+}
+
+// Start starts searching for player in the background, until ctx is done or Stop is called. Timeout and Budget are ignored.
+//
+// Starting a search for the opponent on the opponent's time ponders: as the tree is kept, the search for the next move
+// continues from the subtree of the move the opponent actually plays.
+func (t *MCTS) Start(ctx context.Context, player game.Player) {
+	t.Stop()
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan game.Single, 1)
+	boardHash := t.beginSearch(player)
+	t.Lock()
+	t.cancel = cancel
+	t.done = done
+	t.Unlock()
+
+	go func() {
+		t.searchParallel(ctx, false)
+		done <- t.endSearch(boardHash)
+	}()
+}
+
+// Stop stops the background search started by Start, and returns the best move found. It returns Pass if no search was started.
+func (t *MCTS) Stop() game.Single {
+	t.Lock()
+	cancel, done := t.cancel, t.done
+	t.cancel, t.done = nil, nil
+	t.Unlock()
+	if cancel == nil {
+		return Pass
+	}
+	cancel()
+	return <-done
+}
+
+// Searching returns true if a search is running.
+func (t *MCTS) Searching() bool {
+	running, _ := t.running.Load().(bool)
+	return running
+}
+
+// Analysis returns a snapshot of the current search. It is safe to call while searching.
+func (t *MCTS) Analysis() SearchResult {
+	t.RLock()
+	root := t.root
+	current := t.current
+	t.RUnlock()
+	if root == nilNode || current == nil {
+		return SearchResult{}
+	}
+
+	player := current.ToMove()
+	rootNode := t.nodeFromNaughty(root)
+	retVal := SearchResult{
+		Player:   player,
+		Playouts: atomic.LoadInt32(&t.iter),
+		Visits:   rootNode.Visits(),
+		Winrate:  rootNode.Evaluate(player),
+		Proof:    rootNode.Proof(),
+	}
+	for _, kid := range t.sortedChildren(root, player) {
+		child := t.nodeFromNaughty(kid)
+		if !child.IsValid() {
+			continue
+		}
+		var q float32
+		visits := child.Visits()
+		if visits > 0 {
+			q = child.Evaluate(player)
+		}
+		retVal.Children = append(retVal.Children, ChildResult{
+			Move:   child.Move(),
+			Visits: visits,
+			Q:      q,
+			Prior:  child.Score(),
+			LCB:    lcb(q, visits),
+			Proof:  child.Proof(),
+		})
+	}
+
+	depth := t.PVDepth
+	if depth <= 0 {
+		depth = t.M * t.N
+	}
+	retVal.PV = t.principalVariation(root, player, depth)
+
+	t.RLock()
+	retVal.Move = t.lastMove
+	t.RUnlock()
+	if t.Searching() && len(retVal.Children) > 0 {
+		retVal.Move = retVal.Children[0].Move
+	}
+	return retVal
+}
+
+// sortedChildren returns a copy of the children of a node, sorted with the best for player first.
+func (t *MCTS) sortedChildren(of naughty, player game.Player) []naughty {
+	t.RLock()
+	children := make([]naughty, len(t.children[of]))
+	copy(children, t.children[of])
+	t.RUnlock()
+	sort.Sort(fancySort{underEval: player, l: children, t: t})
+	return children
+}
+
+// principalVariation follows the most visited children from a node, for at most maxDepth moves.
+func (t *MCTS) principalVariation(of naughty, player game.Player, maxDepth int) (retVal []game.Single) {
+	for len(retVal) < maxDepth {
+		children := t.sortedChildren(of, player)
+		if len(children) == 0 {
+			break
+		}
+		best := t.nodeFromNaughty(children[0])
+		if best.Visits() == 0 || !best.IsValid() {
+			break
+		}
+		retVal = append(retVal, best.Move())
+		of = children[0]
+		player = opponent(player)
+	}
+	return
+}
+
+// decided returns true if the most visited child of the root cannot be overtaken by any other child in the rest of the search.
+//
+// The rest of the search is the rest of the Budget, and, if there is a deadline, the iterations that can be expected before it
+// at the rate of the iterations since start.
+func (t *MCTS) decided(start, deadline time.Time) bool {
+	var first, second uint32
+	var n int
+	for _, kid := range t.Children(t.root) {
+		child := t.nodeFromNaughty(kid)
+		if !child.IsActive() {
+			continue
+		}
+		n++
+		switch v := child.Visits(); {
+		case v > first:
+			first, second = v, first
+		case v > second:
+			second = v
+		}
+	}
+	if n == 0 {
+		return false
+	}
+	if n == 1 || t.nodeFromNaughty(t.root).Proof() != Unproven {
+		return true
+	}
+
+	iter := int64(atomic.LoadInt32(&t.iter))
+	remaining := int64(math.MaxInt64)
+	if t.Budget > 0 {
+		remaining = int64(t.Budget) - iter
+	}
+	if !deadline.IsZero() {
+		if elapsed := time.Since(start); elapsed > 0 {
+			expected := int64(float64(iter) * float64(time.Until(deadline)) / float64(elapsed))
+			if expected < remaining {
+				remaining = expected
+			}
+		}
+	}
+	if remaining == math.MaxInt64 {
+		return false
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+	return int64(first-second) > remaining
+}
@@ -0,0 +1,172 @@
+package mcts_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/chewxy/math32"
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/game/mnk"
+	"github.com/gorgonia/agogo/mcts"
+)
+
+func TestMCTS_StartStop(t *testing.T) {
+	g := mnk.TicTacToe()
+	conf := mcts.DefaultConfig(3)
+	tree := mcts.New(g, conf, hashNN{})
+
+	tree.Start(context.Background(), Cross)
+	if !tree.Searching() {
+		t.Fatal("Expected the tree to be searching")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for tree.Analysis().Playouts < 100 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	move := tree.Stop()
+	if tree.Searching() {
+		t.Fatal("Expected the search to be stopped")
+	}
+	if !g.Check(game.PlayerMove{Player: Cross, Single: move}) {
+		t.Fatalf("Expected a legal move. Got %v", move)
+	}
+
+	a := tree.Analysis()
+	if a.Player != Cross {
+		t.Errorf("Expected the analysis to be for %v. Got %v", Cross, a.Player)
+	}
+	if a.Playouts < 100 {
+		t.Errorf("Expected at least 100 playouts. Got %d", a.Playouts)
+	}
+	if len(a.Children) == 0 || len(a.PV) == 0 {
+		t.Fatalf("Expected children and a principal variation. Got %+v", a)
+	}
+	if a.PV[0] != move || a.Children[0].Move != move {
+		t.Errorf("Expected the best move %v to lead the analysis. Got PV %v, first child %v", move, a.PV, a.Children[0].Move)
+	}
+	for i := 1; i < len(a.Children); i++ {
+		if a.Children[i].Visits > a.Children[i-1].Visits {
+			t.Errorf("Expected the children to be sorted by visits. Got %v before %v", a.Children[i-1], a.Children[i])
+		}
+	}
+
+	if stopped := tree.Stop(); stopped != mcts.Pass {
+		t.Errorf("Expected Stop without a search to return Pass. Got %v", stopped)
+	}
+}
+
+func TestMCTS_SearchContext(t *testing.T) {
+	g := mnk.TicTacToe()
+	conf := mcts.DefaultConfig(3)
+	conf.Timeout = time.Minute
+	tree := mcts.New(g, conf, hashNN{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	tree.SearchContext(ctx, Cross)
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Expected the search to stop with the context. Took %v", elapsed)
+	}
+}
+
+func TestMCTS_EarlyStop(t *testing.T) {
+	search := func(earlyStop bool) (game.Single, int32) {
+		g := mnk.TicTacToe()
+		conf := mcts.DefaultConfig(3)
+		conf.Seed = 1337
+		conf.Deterministic = true
+		conf.Budget = 2000
+		conf.EarlyStop = earlyStop
+		tree := mcts.New(g, conf, hashNN{})
+		move := tree.Search(Cross)
+		return move, tree.Analysis().Playouts
+	}
+
+	full, fullPlayouts := search(false)
+	early, earlyPlayouts := search(true)
+	if fullPlayouts != 2000 {
+		t.Errorf("Expected the full budget to be used. Got %d playouts", fullPlayouts)
+	}
+	if earlyPlayouts >= fullPlayouts {
+		t.Errorf("Expected the search to stop early. Got %d playouts", earlyPlayouts)
+	}
+	if early != full {
+		t.Errorf("Expected stopping early to find the same move %v. Got %v", full, early)
+	}
+}
+
+func TestMCTS_Analysis_JSON(t *testing.T) {
+	g := mnk.TicTacToe()
+	conf := mcts.DefaultConfig(3)
+	conf.Seed = 1337
+	conf.Deterministic = true
+	conf.Budget = 500
+	conf.PVDepth = 2
+	tree := mcts.New(g, conf, hashNN{})
+	move := tree.Search(Cross)
+
+	a := tree.Analysis()
+	if a.Move != move {
+		t.Errorf("Expected the result to record the move %v. Got %v", move, a.Move)
+	}
+	if len(a.PV) != 2 {
+		t.Errorf("Expected a principal variation of 2 moves. Got %v", a.PV)
+	}
+	for _, c := range a.Children {
+		if c.LCB > c.Q {
+			t.Errorf("Expected the LCB of %v to be at most its Q. Got %v > %v", c.Move, c.LCB, c.Q)
+		}
+	}
+
+	bs, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b mcts.SearchResult
+	if err := json.Unmarshal(bs, &b); err != nil {
+		t.Fatal(err)
+	}
+	if b.Move != a.Move || b.Visits != a.Visits || len(b.Children) != len(a.Children) || len(b.PV) != len(a.PV) {
+		t.Errorf("Expected the result to survive a JSON round trip. Got %s", bs)
+	}
+	for i := range a.Children {
+		if a.Children[i] != b.Children[i] {
+			t.Errorf("Child %d: expected %+v. Got %+v", i, a.Children[i], b.Children[i])
+		}
+	}
+}
+
+func TestSearchResult_Policy(t *testing.T) {
+	res := mcts.SearchResult{Children: []mcts.ChildResult{
+		{Move: 0, Visits: 6},
+		{Move: mcts.Pass, Visits: 3},
+		{Move: 2, Visits: 0}, // created, but never visited
+	}}
+	policy := res.Policy(3, 1)
+	expected := []float32{6.0 / 9, 0, 0, 3.0 / 9}
+	if len(policy) != len(expected) {
+		t.Fatalf("Expected %d entries. Got %v", len(expected), policy)
+	}
+	for i := range expected {
+		if math32.Abs(policy[i]-expected[i]) > 1e-6 {
+			t.Errorf("Expected %v. Got %v", expected, policy)
+			break
+		}
+	}
+
+	sharp := res.Policy(3, 0.1)
+	if sharp[0] <= policy[0] || sharp[0] > 1 {
+		t.Errorf("Expected a low temperature to sharpen the policy. Got %v", sharp)
+	}
+	if flat := res.Policy(3, 10); flat[0] >= policy[0] {
+		t.Errorf("Expected a high temperature to flatten the policy. Got %v", flat)
+	}
+
+	if p := (mcts.SearchResult{}).Policy(3, 1); p != nil {
+		t.Errorf("Expected no policy without visits. Got %v", p)
+	}
+}
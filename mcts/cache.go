@@ -0,0 +1,127 @@
+package mcts
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/gorgonia/agogo/game"
+)
+
+// evalKey is the key of an evaluation: the hash of the board and the player to move.
+type evalKey struct {
+	hash   game.Zobrist
+	player game.Player
+}
+
+// evaluation is an evaluation of a position by the neural network.
+type evaluation struct {
+	key    evalKey
+	policy []float32
+	value  float32
+}
+
+// EvalCache is a least recently used cache of the evaluations of positions by a neural network.
+//
+// Unlike the search trees, it is kept across searches and games. Trees that search with the same neural network may share
+// a cache. When the weights of the network change, the cache has to be cleared.
+//
+// Positions are only told apart by their hash and the player to move, so two positions with colliding hashes share an evaluation.
+type EvalCache struct {
+	sync.Mutex
+	size    int
+	lru     *list.List // of evaluation, most recently used first
+	entries map[evalKey]*list.Element
+
+	hits, misses int
+}
+
+// NewEvalCache creates a cache of at most size evaluations. If size is not positive, it returns nil, which is a valid cache that never hits.
+func NewEvalCache(size int) *EvalCache {
+	if size <= 0 {
+		return nil
+	}
+	return &EvalCache{
+		size:    size,
+		lru:     list.New(),
+		entries: make(map[evalKey]*list.Element, size),
+	}
+}
+
+// Get returns the evaluation of the state, if it is cached. The returned policy must not be modified.
+func (c *EvalCache) Get(state game.State) (policy []float32, value float32, ok bool) {
+	if c == nil {
+		return nil, 0, false
+	}
+	key := evalKey{state.Hash(), state.ToMove()}
+	c.Lock()
+	defer c.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, 0, false
+	}
+	c.hits++
+	c.lru.MoveToFront(elem)
+	e := elem.Value.(*evaluation)
+	return e.policy, e.value, true
+}
+
+// Put caches the evaluation of the state, evicting the least recently used evaluation if the cache is full.
+func (c *EvalCache) Put(state game.State, policy []float32, value float32) {
+	if c == nil {
+		return
+	}
+	key := evalKey{state.Hash(), state.ToMove()}
+	c.Lock()
+	defer c.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		e := elem.Value.(*evaluation)
+		e.policy, e.value = policy, value
+		c.lru.MoveToFront(elem)
+		return
+	}
+	if c.lru.Len() >= c.size {
+		oldest := c.lru.Back()
+		delete(c.entries, oldest.Value.(*evaluation).key)
+		c.lru.Remove(oldest)
+	}
+	c.entries[key] = c.lru.PushFront(&evaluation{key: key, policy: policy, value: value})
+}
+
+// Len returns the number of cached evaluations.
+func (c *EvalCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.Lock()
+	defer c.Unlock()
+	return c.lru.Len()
+}
+
+// Stats returns the number of hits and misses of the cache.
+func (c *EvalCache) Stats() (hits, misses int) {
+	if c == nil {
+		return 0, 0
+	}
+	c.Lock()
+	defer c.Unlock()
+	return c.hits, c.misses
+}
+
+// Clear removes all the cached evaluations. The hit and miss counters are kept.
+func (c *EvalCache) Clear() {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	c.lru.Init()
+	c.entries = make(map[evalKey]*list.Element, c.size)
+	c.Unlock()
+}
+
+// SetEvalCache makes the tree look up the evaluations of the neural network in c before calling it. A nil c disables the cache.
+func (t *MCTS) SetEvalCache(c *EvalCache) {
+	t.Lock()
+	t.cache = c
+	t.Unlock()
+}
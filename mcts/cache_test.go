@@ -0,0 +1,82 @@
+package mcts_test
+
+import (
+	"testing"
+
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/game/mnk"
+	"github.com/gorgonia/agogo/mcts"
+)
+
+func TestEvalCache(t *testing.T) {
+	c := mcts.NewEvalCache(2)
+	g := mnk.TicTacToe()
+	states := make([]game.State, 3)
+	for i := range states {
+		s := g.Clone().(game.State)
+		s = s.Apply(game.PlayerMove{Player: Cross, Single: game.Single(i)}).(game.State)
+		states[i] = s
+	}
+
+	c.Put(states[0], []float32{0}, 0)
+	c.Put(states[1], []float32{1}, 1)
+	if _, v, ok := c.Get(states[0]); !ok || v != 0 {
+		t.Fatalf("Expected states[0] to be cached. Got %v, %v", v, ok)
+	}
+	c.Put(states[2], []float32{2}, 2) // evicts states[1], the least recently used
+	if c.Len() != 2 {
+		t.Errorf("Expected 2 cached evaluations. Got %d", c.Len())
+	}
+	if _, _, ok := c.Get(states[1]); ok {
+		t.Error("Expected states[1] to be evicted")
+	}
+	if _, v, ok := c.Get(states[2]); !ok || v != 2 {
+		t.Errorf("Expected states[2] to be cached. Got %v, %v", v, ok)
+	}
+
+	// the player to move is part of the key
+	s := states[0].Clone().(game.State)
+	s.SetToMove(Cross)
+	if _, _, ok := c.Get(s); ok {
+		t.Error("Expected the same board with another player to move to miss")
+	}
+
+	if hits, misses := c.Stats(); hits != 2 || misses != 2 {
+		t.Errorf("Expected 2 hits and 2 misses. Got %d and %d", hits, misses)
+	}
+	c.Clear()
+	if c.Len() != 0 {
+		t.Errorf("Expected an empty cache. Got %d evaluations", c.Len())
+	}
+
+	var nilCache *mcts.EvalCache
+	nilCache.Put(states[0], nil, 0)
+	if _, _, ok := nilCache.Get(states[0]); ok {
+		t.Error("Expected a nil cache to never hit")
+	}
+}
+
+func TestEvalCache_Shared(t *testing.T) {
+	cache := mcts.NewEvalCache(1 << 12)
+	search := func() int {
+		conf := mcts.DefaultConfig(3)
+		conf.Seed = 1337
+		conf.Deterministic = true
+		conf.Budget = 500
+		nn := &countingNN{}
+		tree := mcts.New(mnk.TicTacToe(), conf, nn)
+		tree.SetEvalCache(cache)
+		tree.Search(Cross)
+		return nn.calls
+	}
+
+	if calls := search(); calls == 0 {
+		t.Fatal("Expected the first search to call the inferencer")
+	}
+	if calls := search(); calls != 0 {
+		t.Errorf("Expected the second tree to find every evaluation in the shared cache. Got %d calls", calls)
+	}
+	if hits, _ := cache.Stats(); hits == 0 {
+		t.Error("Expected the cache to be hit")
+	}
+}
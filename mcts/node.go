@@ -42,6 +42,8 @@ type Node struct {
 	minPSARatioChildren uint32 // actually float32. minimum P(s,a) ratio for the children. Default to 2
 	score               uint32 // Policy estimate for taking the move above (from NN)
 	value               uint32 // value from the neural network
+	proof               uint32 // proven result of the node. See Proof
+	noised              uint32 // 1 once Dirichlet noise has been mixed into the priors of the children
 
 	// naughty things
 	id   naughty // index to the children allocation
@@ -143,9 +145,12 @@ func (n *Node) MinPsaRatio() float32 {
 
 func (n *Node) ID() int { return int(n.id) }
 
-// Evaluate evaluates a move made by a player
+// Evaluate evaluates a move made by a player. A node that has not been visited yet is evaluated by the NN.
 func (n *Node) Evaluate(player game.Player) float32 {
 	visits := n.Visits()
+	if visits == 0 {
+		return n.NNEvaluate(player)
+	}
 	blackScores := n.BlackScores()
 	if player == White {
 		blackScores += n.VirtualLoss()
@@ -186,11 +191,7 @@ func (n *Node) Select(of game.Player) naughty {
 		}
 	}
 
-	// the upper bound formula is as such
-	// U(s, a) = Q(s, a) + tree.PUCT * P(s, a) * ((sqrt(parent visits))/ (1+visits to this node))
-	//
-	// where
-	// U(s, a) = upper confidence bound given state and action
+	// the upper bound U(s, a) of every child is computed by the selection policy, from
 	// Q(s, a) = reward of taking the action given the state
 	// P(s, a) = iniital probability/estimate of taking an action from the state given according to the policy
 	//
@@ -201,10 +202,15 @@ func (n *Node) Select(of game.Player) naughty {
 	// Given the state and action is already known and encoded into Node itself,it doesn't have to be a function
 	// like in most MCTS tutorials. This allows it to be slightly more performant (i.e. a AoS-ish data structure)
 
+	// before any child is visited, the priors still break the tie
+	if parentVisits == 0 {
+		parentVisits = 1
+	}
+
 	var best naughty
 	var bestValue float32 = math32.Inf(-1)
-	fpu := n.NNEvaluate(of)                         // first play urgency is the value predicted by the NN
-	numerator := math32.Sqrt(float32(parentVisits)) // in order to find the stochastic policy, we need to normalize the count
+	// first play urgency is the value predicted by the NN, reduced by how much of the policy has been explored already
+	fpu := n.NNEvaluate(of) - tree.FPUReduction*math32.Sqrt(sumScore)
 
 	for _, kid := range children {
 		child := tree.nodeFromNaughty(kid)
@@ -218,10 +224,10 @@ func (n *Node) Select(of game.Player) naughty {
 			qsa = child.Evaluate(of) // but if this node has been visited before, Q from the node is used.
 		}
 		psa := child.Score()
-		denominator := 1.0 + float32(visits)
-		lastTerm := (numerator / denominator)
-		puct := tree.PUCT * psa * lastTerm
-		usa := qsa + puct
+		usa := tree.policy.Score(qsa, psa, visits, parentVisits)
+		if tree.Solver && child.Proof().Loses(of) {
+			usa = provenLoss
+		}
 
 		if usa > bestValue {
 			bestValue = usa
@@ -305,4 +311,6 @@ func (n *Node) reset() {
 	atomic.StoreUint32(&n.score, 0)
 	atomic.StoreUint32(&n.value, 0)
 	atomic.StoreUint32(&n.virtualLoss, 0)
+	atomic.StoreUint32(&n.proof, 0)
+	atomic.StoreUint32(&n.noised, 0)
 }
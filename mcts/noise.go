@@ -0,0 +1,72 @@
+package mcts
+
+import (
+	"math/rand"
+	"sync/atomic"
+
+	"github.com/chewxy/math32"
+)
+
+// SetNoise enables or disables the Dirichlet noise on the priors of the root's children.
+// Noise is meant for self play only, where it helps exploring different openings. It is disabled by default.
+func (t *MCTS) SetNoise(on bool) {
+	t.Lock()
+	t.noise = on
+	t.Unlock()
+}
+
+// addDirichletNoise mixes Dirichlet noise into the priors of the children of the given node:
+//
+//	P(s, a) = (1 - ε) * P(s, a) + ε * η_a, where η ~ Dir(α)
+//
+// The noise is only mixed in once per node, so that searching the same root again does not drift the priors further from the NN.
+func (t *MCTS) addDirichletNoise(of naughty) {
+	children := t.Children(of)
+	if len(children) == 0 {
+		return
+	}
+	if !atomic.CompareAndSwapUint32(&t.nodeFromNaughty(of).noised, 0, 1) {
+		return
+	}
+	eta := make([]float32, len(children))
+	var sum float32
+	for i := range eta {
+		eta[i] = sampleGamma(t.rand, t.DirichletAlpha)
+		sum += eta[i]
+	}
+	if sum < math32.SmallestNonzeroFloat32 {
+		return
+	}
+	ε := t.DirichletEpsilon
+	for i, kid := range children {
+		child := t.nodeFromNaughty(kid)
+		score := (1-ε)*child.Score() + ε*eta[i]/sum
+		atomic.StoreUint32(&child.score, math32.Float32bits(score))
+	}
+}
+
+// sampleGamma samples from a Gamma(α, 1) distribution using the method of Marsaglia and Tsang.
+func sampleGamma(r *rand.Rand, α float32) float32 {
+	if α < 1 {
+		// boost: if X ~ Gamma(α+1) and U ~ Uniform(0, 1), then X * U^(1/α) ~ Gamma(α)
+		u := r.Float32()
+		return sampleGamma(r, α+1) * math32.Pow(u, 1/α)
+	}
+	d := α - 1.0/3.0
+	c := 1 / math32.Sqrt(9*d)
+	for {
+		var x, v float32
+		for v <= 0 {
+			x = float32(r.NormFloat64())
+			v = 1 + c*x
+		}
+		v = v * v * v
+		u := r.Float32()
+		if u < 1-0.0331*x*x*x*x {
+			return d * v
+		}
+		if math32.Log(u) < 0.5*x*x+d*(1-v+math32.Log(v)) {
+			return d * v
+		}
+	}
+}
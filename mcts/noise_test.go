@@ -0,0 +1,72 @@
+package mcts
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/chewxy/math32"
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/game/mnk"
+)
+
+func TestSampleGamma(t *testing.T) {
+	r := rand.New(rand.NewSource(1337))
+	for _, α := range []float32{0.03, 0.3, 1, 2.5} {
+		var sum float32
+		n := 20000
+		for i := 0; i < n; i++ {
+			x := sampleGamma(r, α)
+			if x < 0 || math32.IsNaN(x) || math32.IsInf(x, 0) {
+				t.Fatalf("α %v: invalid sample %v", α, x)
+			}
+			sum += x
+		}
+		// the mean of Gamma(α, 1) is α
+		if mean := sum / float32(n); math32.Abs(mean-α) > 0.05*α+0.01 {
+			t.Errorf("α %v: expected a mean of about %v. Got %v", α, α, mean)
+		}
+	}
+}
+
+func TestMCTS_addDirichletNoise(t *testing.T) {
+	conf := DefaultConfig(3)
+	conf.DirichletAlpha = 0.3
+	conf.DirichletEpsilon = 0.25
+	tree := New(mnk.TicTacToe(), conf, nil)
+	root := tree.New(Pass, 0, 0)
+	for i := 0; i < 4; i++ {
+		tree.nodeFromNaughty(root).AddChild(tree.New(game.Single(i), 0.25, 0))
+	}
+
+	tree.addDirichletNoise(root)
+	var sum float32
+	var changed bool
+	for _, kid := range tree.Children(root) {
+		score := tree.nodeFromNaughty(kid).Score()
+		if score < 0.75*0.25 {
+			t.Errorf("At most epsilon of the prior should be replaced by noise. Got %v", score)
+		}
+		if score != 0.25 {
+			changed = true
+		}
+		sum += score
+	}
+	if !changed {
+		t.Error("Expected the priors to change")
+	}
+	if math32.Abs(sum-1) > 1e-5 {
+		t.Errorf("Expected the priors to still sum to 1. Got %v", sum)
+	}
+
+	// searching the same root again does not add noise on top of the noise
+	var before []float32
+	for _, kid := range tree.Children(root) {
+		before = append(before, tree.nodeFromNaughty(kid).Score())
+	}
+	tree.addDirichletNoise(root)
+	for i, kid := range tree.Children(root) {
+		if score := tree.nodeFromNaughty(kid).Score(); score != before[i] {
+			t.Errorf("Expected the noise to be mixed in once. Child %d changed from %v to %v", i, before[i], score)
+		}
+	}
+}
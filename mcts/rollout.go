@@ -0,0 +1,126 @@
+package mcts
+
+import (
+	"math/rand"
+	"sync"
+
+	"github.com/gorgonia/agogo/game"
+)
+
+// RolloutPolicy picks the moves of the playouts of a Rollout. It returns Pass if the player to move has no move to play.
+// Heuristic policies may be written for any game, and fall back on RandomRollout.
+type RolloutPolicy func(state game.State, r *rand.Rand) game.Single
+
+// RandomRollout is the RolloutPolicy that plays uniformly random legal moves. It only passes when there is no other legal move.
+func RandomRollout(state game.State, r *rand.Rand) game.Single {
+	player := state.ToMove()
+	var moves []game.Single
+	for i := 0; i < state.ActionSpace(); i++ {
+		if state.Check(game.PlayerMove{Player: player, Single: game.Single(i)}) {
+			moves = append(moves, game.Single(i))
+		}
+	}
+	if len(moves) == 0 {
+		return Pass
+	}
+	return moves[r.Intn(len(moves))]
+}
+
+// Rollout is an Inferencer without a neural network: it evaluates a position by playing it out to the end with a RolloutPolicy,
+// and gives uniform priors to every move. It is meant to be searched with SelectUCB1, which ignores the priors.
+//
+// A playout that does not end in MaxMoves moves is adjudicated by the scores of the players.
+type Rollout struct {
+	Rollouts int           // number of playouts of every evaluation. If 0, it is 1
+	Policy   RolloutPolicy // if nil, it is RandomRollout
+	MaxMoves int           // maximum number of moves of a playout. If 0, it is twice the action space
+
+	mu sync.Mutex // guards r, which seeds the random number generator of every evaluation
+	r  *rand.Rand
+}
+
+// NewRollout creates a Rollout that plays rollouts playouts of every position with policy. If seed is 0, it is seeded from the clock.
+func NewRollout(rollouts int, policy RolloutPolicy, seed int64) *Rollout {
+	return &Rollout{
+		Rollouts: rollouts,
+		Policy:   policy,
+		r:        NewRand(seed),
+	}
+}
+
+// Infer returns uniform priors, and the mean result of the playouts for the player to move: 1 for a win, 0.5 for a draw and 0 for a loss.
+func (r *Rollout) Infer(state game.State) (policy []float32, value float32) {
+	policy = make([]float32, state.ActionSpace()+1)
+	for i := range policy {
+		policy[i] = 1 / float32(len(policy))
+	}
+
+	n := r.Rollouts
+	if n <= 0 {
+		n = 1
+	}
+	player := state.ToMove()
+
+	// the lock is only held to draw a seed, so that concurrent searches play out their positions in parallel
+	r.mu.Lock()
+	if r.r == nil {
+		r.r = NewRand(0)
+	}
+	seed := r.r.Int63()
+	r.mu.Unlock()
+	rng := rand.New(rand.NewSource(seed))
+	for i := 0; i < n; i++ {
+		switch r.playout(state, rng) {
+		case player:
+			value++
+		case game.Player(game.None):
+			value += 0.5
+		}
+	}
+	return policy, value / float32(n)
+}
+
+// playout plays a clone of state out to the end with the moves drawn from rng, and returns the winner.
+func (r *Rollout) playout(state game.State, rng *rand.Rand) game.Player {
+	policy := r.Policy
+	if policy == nil {
+		policy = RandomRollout
+	}
+	maxMoves := r.MaxMoves
+	if maxMoves <= 0 {
+		maxMoves = 2 * state.ActionSpace()
+	}
+
+	g := state.Clone()
+	var passes int
+	for i := 0; i < maxMoves; i++ {
+		if ended, winner := g.Ended(); ended {
+			return winner
+		}
+		player := g.ToMove()
+		move := policy(g, rng)
+		if move.IsResignation() {
+			return opponent(player)
+		}
+		if move.IsPass() {
+			passes++
+			if passes >= 2 || !g.Check(game.PlayerMove{Player: player, Single: move}) {
+				break
+			}
+		} else {
+			passes = 0
+		}
+		g = g.Apply(game.PlayerMove{Player: player, Single: move})
+	}
+	if ended, winner := g.Ended(); ended {
+		return winner
+	}
+	black, white := g.Score(Black), g.Score(White)
+	switch {
+	case black > white:
+		return Black
+	case white > black:
+		return White
+	}
+	return game.Player(game.None)
+}
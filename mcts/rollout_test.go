@@ -0,0 +1,95 @@
+package mcts_test
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/mcts"
+)
+
+func TestRollout_Infer(t *testing.T) {
+	// X O X
+	// X O O
+	// O X .
+	g := position(0, 1, 2, 4, 3, 5, 7, 6)
+	policy, value := mcts.NewRollout(10, nil, 1337).Infer(g)
+	if len(policy) != g.ActionSpace()+1 {
+		t.Fatalf("Expected a prior for every move and passing. Got %v", policy)
+	}
+	if value != 0.5 {
+		t.Errorf("The only move left draws. Expected a value of 0.5. Got %v", value)
+	}
+	if ended, _ := g.Ended(); ended || g.Board()[8] != game.None {
+		t.Error("The playouts should not modify the evaluated state")
+	}
+
+	// a heuristic policy that resigns loses every playout
+	resign := func(state game.State, r *rand.Rand) game.Single { return mcts.Resign }
+	empty := position()
+	empty.SetToMove(Cross)
+	if _, value = mcts.NewRollout(10, resign, 1337).Infer(empty); value != 0 {
+		t.Errorf("Expected a value of 0 when the player to move resigns. Got %v", value)
+	}
+
+	_, v1 := mcts.NewRollout(5, nil, 1337).Infer(position(4))
+	_, v2 := mcts.NewRollout(5, nil, 1337).Infer(position(4))
+	if v1 != v2 {
+		t.Errorf("The same seed should play the same playouts. Got %v and %v", v1, v2)
+	}
+}
+
+func TestRollout_Concurrent(t *testing.T) {
+	// every playout waits for the playouts of the other evaluations to start, which only happens if they run in parallel
+	const evaluations = 4
+	var started sync.WaitGroup
+	started.Add(evaluations)
+	var calls int32
+	wait := func(state game.State, r *rand.Rand) game.Single {
+		if atomic.AddInt32(&calls, 1) <= evaluations {
+			started.Done()
+		}
+		started.Wait()
+		return mcts.RandomRollout(state, r)
+	}
+
+	rollout := mcts.NewRollout(1, wait, 1337)
+	done := make(chan struct{})
+	go func() {
+		var wg sync.WaitGroup
+		for i := 0; i < evaluations; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rollout.Infer(position(4))
+			}()
+		}
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Expected concurrent evaluations to play out in parallel")
+	}
+}
+
+func TestRollout_Search(t *testing.T) {
+	// X X .
+	// O O .
+	// . . .
+	g := position(0, 3, 1, 4)
+	conf := mcts.DefaultConfig(3)
+	conf.Selection = mcts.SelectUCB1
+	conf.PUCT = 1.4
+	conf.Budget = 500
+	conf.Deterministic = true
+	conf.Seed = 1337
+	tree := mcts.New(g, conf, mcts.NewRollout(1, nil, conf.Seed))
+	if move := tree.Search(Cross); move != 2 {
+		t.Errorf("Expected the search with rollouts to complete the top row. Got %v", move)
+	}
+}
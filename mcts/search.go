@@ -20,7 +20,7 @@ so that a search can be written for any other games but uses the same data struc
 */
 
 const (
-	MAXTREESIZE = 25000000 // a tree is at max allowed this many nodes - at about 56 bytes per node that is 1.2GB of memory required
+	MAXTREESIZE = 25000000 // default maximum number of nodes of a tree. See Config.MaxNodes
 )
 
 func opponent(p game.Player) game.Player {
@@ -75,11 +75,12 @@ func (s *searchState) incrementPlayout() {
 func (s *searchState) isRunning() bool {
 	t := treeFromUintptr(s.tree)
 	running := t.running.Load().(bool)
-	return running && t.nodeCount() < MAXTREESIZE
+	return running && t.nodeCount() < t.maxNodes()
 }
 
 func (s *searchState) minPsaRatio() float32 {
-	ratio := float32(s.nodeCount()) / float32(MAXTREESIZE)
+	t := treeFromUintptr(s.tree)
+	ratio := float32(s.nodeCount()) / float32(t.maxNodes())
 	switch {
 	case ratio > 0.95:
 		return 0.01
@@ -89,7 +90,40 @@ func (s *searchState) minPsaRatio() float32 {
 	return 0
 }
 
+// Search searches for the best move of player, until the timeout. In a deterministic search, it searches for exactly Budget iterations instead.
 func (t *MCTS) Search(player game.Player) (retVal game.Single) {
+	return t.SearchContext(context.Background(), player)
+}
+
+// SearchContext is like Search, but it also stops when ctx is done, or, with EarlyStop, once the best move is decided.
+// If the tree has a time manager, the time manager allocates the time of the search instead of Timeout.
+// The tree is kept, so that the next search can reuse it.
+func (t *MCTS) SearchContext(ctx context.Context, player game.Player) (retVal game.Single) {
+	start := time.Now()
+	boardHash := t.beginSearch(player)
+	if t.Deterministic {
+		t.searchSerial(ctx)
+		return t.endSearch(boardHash)
+	}
+
+	timeout, earlyStop := t.Timeout, t.EarlyStop
+	if t.clock != nil {
+		if d := t.clock.Allocate(t.current.MoveNumber()); d > 0 {
+			timeout, earlyStop = d-time.Since(start), true
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	t.searchParallel(ctx, earlyStop)
+	cancel()
+	retVal = t.endSearch(boardHash)
+	if t.clock != nil {
+		t.clock.Spend(time.Since(start))
+	}
+	return retVal
+}
+
+// beginSearch prepares the root of a search for player. It returns the hash of the board being searched.
+func (t *MCTS) beginSearch(player game.Player) game.Zobrist {
 	t.log("SEARCH. Player %v\n%v", player, t.current)
 	t.updateRoot()
 	t.current.SetToMove(player)
@@ -107,8 +141,51 @@ func (t *MCTS) Search(player game.Player) (retVal game.Single) {
 	// }
 
 	t.prepareRoot(player, t.current)
+
+	atomic.StoreInt32(&t.iter, 0)
+	t.running.Store(true)
+	return boardHash
+}
+
+// endSearch picks the best move once a search is over.
+func (t *MCTS) endSearch(boardHash game.Zobrist) game.Single {
+	move := t.pickMove(boardHash)
+	t.Lock()
+	t.lastMove = move
+	t.Unlock()
+	t.running.Store(false)
+	return move
+}
+
+func (t *MCTS) pickMove(boardHash game.Zobrist) (retVal game.Single) {
 	root := t.nodeFromNaughty(t.root)
+	if !root.HasChildren() {
+		policy, _ := t.infer(t.current)
+		moveID := argmax(policy)
+		if moveID > t.current.ActionSpace() {
+			return Pass
+		}
+		t.log("Returning Early. Best %v", moveID)
+		return game.Single(moveID)
+	}
 
+	retVal = t.bestMove()
+	t.prev = t.current.Clone().(game.State)
+	t.log("Move Number %d, Iterations %d Playouts: %v Nodes: %v. Best: %v", t.current.MoveNumber(), t.iter, t.playouts, len(t.nodes), retVal)
+	t.log("DUMMY")
+	// log.Printf("\n%v", t.prev)
+	// log.Printf("\tIterations %d Playouts: %v Nodes: %v. Best move %v Player %v", iter, t.playouts, len(t.nodes), retVal, player)
+
+	// update the cached policies.
+	// Again, nothing like having side effects to what appears to be a straightforwards
+	// pure function eh?
+	t.cachedPolicies[sa{boardHash, retVal}]++
+
+	return retVal
+}
+
+// searchParallel searches with one goroutine per CPU until ctx is done. If earlyStop is true, it also stops once the best move is decided.
+func (t *MCTS) searchParallel(ctx context.Context, earlyStop bool) {
 	ch := make(chan *searchState, runtime.NumCPU())
 	var wg sync.WaitGroup
 	for i := 0; i < runtime.NumCPU(); i++ {
@@ -122,45 +199,57 @@ func (t *MCTS) Search(player game.Player) (retVal game.Single) {
 		ch <- ss
 	}
 
-	var iter int32
-	t.running.Store(true)
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	for i := 0; i < runtime.NumCPU(); i++ {
 		wg.Add(1)
-		go doSearch(t.root, &iter, ch, ctx, &wg)
+		go doSearch(t.root, &t.iter, ch, ctx, &wg)
+	}
+
+	if earlyStop {
+		start := time.Now()
+		deadline, _ := ctx.Deadline()
+		tick := time.NewTicker(earlyStopInterval)
+	loop:
+		for {
+			select {
+			case <-tick.C:
+				if t.decided(start, deadline) {
+					break loop
+				}
+			case <-ctx.Done():
+				break loop
+			}
+		}
+		tick.Stop()
+	} else {
+		<-ctx.Done()
 	}
-	<-time.After(t.Timeout)
 	cancel()
 
 	// TODO
 	// reactivate all pruned children
 	wg.Wait()
 	close(ch)
+}
 
-	root = t.nodeFromNaughty(t.root)
-	if !root.HasChildren() {
-		policy, _ := t.nn.Infer(t.current)
-		moveID := argmax(policy)
-		if moveID > t.current.ActionSpace() {
-			return Pass
+// searchSerial searches in the calling goroutine for exactly Budget iterations, unless ctx is done or, with EarlyStop, the best move is decided.
+func (t *MCTS) searchSerial(ctx context.Context) {
+	s := &searchState{
+		tree:     ptrFromTree(t),
+		current:  t.current,
+		root:     t.root,
+		maxDepth: t.M * t.N,
+	}
+	for atomic.LoadInt32(&t.iter) < t.Budget && ctx.Err() == nil {
+		if t.EarlyStop && t.decided(time.Time{}, time.Time{}) {
+			break
 		}
-		t.log("Returning Early. Best %v", moveID)
-		return game.Single(moveID)
+		current := s.current.Clone().(game.State)
+		if res := s.pipeline(current, t.root); !isNullResult(res) {
+			s.incrementPlayout()
+		}
+		atomic.AddInt32(&t.iter, 1)
 	}
-
-	retVal = t.bestMove()
-	t.prev = t.current.Clone().(game.State)
-	t.log("Move Number %d, Iterations %d Playouts: %v Nodes: %v. Best: %v", t.current.MoveNumber(), iter, t.playouts, len(t.nodes), retVal)
-	t.log("DUMMY")
-	// log.Printf("\n%v", t.prev)
-	// log.Printf("\tIterations %d Playouts: %v Nodes: %v. Best move %v Player %v", iter, t.playouts, len(t.nodes), retVal, player)
-
-	// update the cached policies.
-	// Again, nothing like having side effects to what appears to be a straightforwards
-	// pure function eh?
-	t.cachedPolicies[sa{boardHash, retVal}]++
-
-	return retVal
 }
 
 func doSearch(start naughty, iterBudget *int32, ch chan *searchState, ctx context.Context, wg *sync.WaitGroup) {
@@ -222,16 +311,49 @@ func (s *searchState) pipeline(current game.State, start naughty) (retVal Result
 	n.addVirtualLoss()
 	t.log("\t%p PIPELINE: %v", s, n)
 
-	// EXPAND and SIMULATE
+	// SOLVE: the result of a proven node is exact. It needs no expansion nor selection
 	isExpandable := n.IsExpandable(0)
+	if t.Solver {
+		if n.Proof() == Unproven {
+			if ended, winner := current.Ended(); ended {
+				n.prove(proofOf(winner))
+			}
+		}
+		if proof := n.Proof(); proof != Unproven {
+			retVal = Result(proof.score())
+			isExpandable = false
+		}
+	}
+
+	// EXPAND and SIMULATE
 	if isExpandable && current.Passes() >= 2 {
 		retVal = Result(combinedScore(current))
-	} else if isExpandable && nodeCount < MAXTREESIZE {
+	} else if isExpandable && nodeCount < t.maxNodes() {
 		hadChildren := n.HasChildren()
 		value, ok := s.expandAndSimulate(start, current, s.minPsaRatio())
 		if !hadChildren && ok {
 			retVal = Result(value)
+			if t.tt != nil {
+				// a transposed position has been evaluated by more than the neural network
+				if score, ok := t.tt.score(current); ok {
+					retVal = Result(score)
+				}
+			}
 		}
+	} else if isExpandable && !n.HasChildren() {
+		// the tree is full. The leaf is still evaluated, but not expanded
+		_, value := t.infer(current)
+		if player == White {
+			value = 1 - value
+		}
+		retVal = Result(value)
+	}
+
+	// the state is modified by the recursion, so the transposition is found before
+	var ref ttRef
+	var transposed bool
+	if t.tt != nil {
+		ref, transposed = t.tt.ref(current)
 	}
 
 	// SELECT and RECURSE
@@ -245,11 +367,17 @@ func (s *searchState) pipeline(current game.State, start naughty) (retVal Result
 			current = current.Apply(pm).(game.State)
 			retVal = s.pipeline(current, next.id)
 		}
+		if t.Solver {
+			t.updateProof(n, player)
+		}
 	}
 
 	// BACKPROPAGATE
 	if !isNullResult(retVal) {
 		n.Update(float32(retVal)) // nothing says non functional programs like side effects. Insert more functional programming circle jerk here.
+		if transposed {
+			t.tt.update(ref, float32(retVal))
+		}
 	}
 	n.undoVirtualLoss()
 	s.depth--
@@ -272,7 +400,7 @@ func (s *searchState) expandAndSimulate(parent naughty, state game.State, minPsa
 	}
 	// get scored moves
 	var policy []float32              // boardSize + 1
-	policy, value = t.nn.Infer(state) // get policy probability, value from neural network
+	policy, value = t.infer(state)    // get policy probability, value from neural network
 	passProb := policy[len(policy)-1] // probability of a pass is the last in the policy
 	player := state.ToMove()
 	if player == White {
@@ -364,6 +492,11 @@ func (t *MCTS) bestMove() game.Single {
 	firstChild := t.nodeFromNaughty(children[0])
 	bestMove := firstChild.Move()
 	bestScore := firstChild.Evaluate(player)
+	if t.Solver {
+		if move, ok := t.provenMove(children, player); ok {
+			return move
+		}
+	}
 
 	root := t.nodeFromNaughty(t.root)
 	switch {
@@ -398,6 +531,10 @@ func (t *MCTS) prepareRoot(player game.Player, state game.State) {
 		value, _ = t.expandAndSimulate(t.root, state, t.minPsaRatio())
 	}
 
+	if t.noise && t.DirichletEpsilon > 0 && t.DirichletAlpha > 0 {
+		t.addDirichletNoise(t.root)
+	}
+
 	if hadChildren {
 		value = root.Evaluate(player)
 	} else {
@@ -474,6 +611,10 @@ func (t *MCTS) updateRoot() {
 	t.freeables = t.freeables[:0]
 	player := t.searchState.current.ToMove()
 	if !t.newRootState() || t.searchState.root == nilNode {
+		// the old tree cannot be reached from the new position
+		if t.searchState.root != nilNode {
+			t.freeSubtree(t.searchState.root)
+		}
 		// search for the first useful
 		if ok := t.searchState.current.Check(game.PlayerMove{player, Pass}); ok {
 			t.searchState.root = t.New(Pass, 0, 0)
@@ -489,7 +630,6 @@ func (t *MCTS) updateRoot() {
 	t.log("freables %d", len(t.freeables))
 	t.searchState.prev = nil
 	root := t.nodeFromNaughty(t.searchState.root)
-	atomic.StoreInt32(&t.nc, int32(root.countChildren()))
 
 	// if root has no children
 	children := t.Children(t.searchState.root)
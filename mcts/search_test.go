@@ -0,0 +1,70 @@
+package mcts_test
+
+import (
+	"testing"
+
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/game/mnk"
+	"github.com/gorgonia/agogo/mcts"
+)
+
+// hashNN is a deterministic inferencer whose outputs vary with the position, so that searches are not trivial.
+type hashNN struct{}
+
+func (hashNN) Infer(state game.State) (policy []float32, value float32) {
+	policy = make([]float32, state.ActionSpace()+1)
+	h := uint64(state.Hash())
+	var sum float32
+	for i := range policy {
+		policy[i] = float32((h>>uint(i*3))&7) + 1
+		sum += policy[i]
+	}
+	for i := range policy {
+		policy[i] /= sum
+	}
+	return policy, float32(h%100) / 100
+}
+
+// playDeterministic plays a full game of tic tac toe with a seeded, deterministic search, and returns the moves played
+// and the number of nodes of the tree after every move.
+func playDeterministic(seed int64) (moves []game.Single, nodes []int) {
+	g := mnk.TicTacToe()
+	conf := mcts.DefaultConfig(3)
+	conf.Seed = seed
+	conf.Deterministic = true
+	conf.Budget = 200
+	conf.RandomCount = 4 // exercise the randomized move selection
+	conf.DirichletAlpha = 0.3
+	conf.DirichletEpsilon = 0.25
+	t := mcts.New(g, conf, hashNN{})
+	t.SetNoise(true)
+
+	player := Cross
+	for ended, _ := g.Ended(); !ended; ended, _ = g.Ended() {
+		best := t.Search(player)
+		moves = append(moves, best)
+		nodes = append(nodes, t.Nodes())
+		g = g.Apply(game.PlayerMove{Player: player, Single: best}).(*mnk.MNK)
+		t.SetGame(g)
+		player = opponent(player)
+	}
+	return moves, nodes
+}
+
+func TestMCTS_Deterministic(t *testing.T) {
+	moves, nodes := playDeterministic(1337)
+	if len(moves) == 0 {
+		t.Fatal("Expected moves to be played")
+	}
+	for i := 0; i < 3; i++ {
+		moves2, nodes2 := playDeterministic(1337)
+		if len(moves2) != len(moves) {
+			t.Fatalf("Run %d: expected %d moves. Got %d", i, len(moves), len(moves2))
+		}
+		for j := range moves {
+			if moves[j] != moves2[j] || nodes[j] != nodes2[j] {
+				t.Fatalf("Run %d: move %d differs. Expected %v (%d nodes). Got %v (%d nodes)", i, j, moves[j], nodes[j], moves2[j], nodes2[j])
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package mcts
+
+import "github.com/chewxy/math32"
+
+// SelectionPolicy is the tree policy: it scores the children of a node, and the search selects the child with the highest score.
+type SelectionPolicy interface {
+	// Score scores a child. q is the expected score of the child for the player selecting it, prior is the probability given to the
+	// child by the neural network, visits is the number of visits of the child and parentVisits the sum of the visits of its siblings.
+	Score(q, prior float32, visits, parentVisits uint32) float32
+}
+
+// Selection is a built-in SelectionPolicy, to be chosen in a Config.
+type Selection int
+
+const (
+	SelectPUCT      Selection = iota // PUCTPolicy, with Config.PUCT as the exploration constant
+	SelectAlphaZero                  // AlphaZeroPolicy, with Config.CBase and Config.CInit
+	SelectUCB1                       // UCB1Policy, with Config.PUCT as the exploration constant
+	MAXSELECTION
+)
+
+func (s Selection) String() string {
+	switch s {
+	case SelectPUCT:
+		return "PUCT"
+	case SelectAlphaZero:
+		return "AlphaZero"
+	case SelectUCB1:
+		return "UCB1"
+	}
+	return "UNKNOWN SELECTION"
+}
+
+// PUCTPolicy is the polynomial upper confidence tree formula of AlphaGo Zero:
+//
+//	U(s, a) = Q(s, a) + C * P(s, a) * sqrt(N(s)) / (1 + N(s, a))
+type PUCTPolicy struct {
+	C float32
+}
+
+func (p PUCTPolicy) Score(q, prior float32, visits, parentVisits uint32) float32 {
+	return q + p.C*prior*math32.Sqrt(float32(parentVisits))/(1+float32(visits))
+}
+
+// AlphaZeroPolicy is the PUCT formula of AlphaZero, where the exploration slowly grows with the visits of the parent:
+//
+//	C(s) = log((1 + N(s) + CBase) / CBase) + CInit
+//
+// AlphaZero used CBase = 19652 and CInit = 1.25.
+type AlphaZeroPolicy struct {
+	CBase, CInit float32
+}
+
+func (p AlphaZeroPolicy) Score(q, prior float32, visits, parentVisits uint32) float32 {
+	c := math32.Log((1+float32(parentVisits)+p.CBase)/p.CBase) + p.CInit
+	return q + c*prior*math32.Sqrt(float32(parentVisits))/(1+float32(visits))
+}
+
+// UCB1Policy is the UCB1 formula, which ignores the priors. It is meant for searches without a neural network,
+// such as with random rollouts:
+//
+//	U(s, a) = Q(s, a) + C * sqrt(ln(N(s)) / N(s, a))
+//
+// Children that have not been visited are selected first.
+type UCB1Policy struct {
+	C float32
+}
+
+func (p UCB1Policy) Score(q, prior float32, visits, parentVisits uint32) float32 {
+	if visits == 0 {
+		return math32.Inf(1)
+	}
+	if parentVisits < 1 {
+		parentVisits = 1
+	}
+	return q + p.C*math32.Sqrt(math32.Log(float32(parentVisits))/float32(visits))
+}
+
+// selectionPolicy returns the built-in selection policy chosen by the config.
+func (c Config) selectionPolicy() SelectionPolicy {
+	switch c.Selection {
+	case SelectAlphaZero:
+		return AlphaZeroPolicy{CBase: c.CBase, CInit: c.CInit}
+	case SelectUCB1:
+		return UCB1Policy{C: c.PUCT}
+	}
+	return PUCTPolicy{C: c.PUCT}
+}
+
+// SetSelectionPolicy replaces the selection policy of the config with p, which need not be a built-in one.
+func (t *MCTS) SetSelectionPolicy(p SelectionPolicy) {
+	t.Lock()
+	t.policy = p
+	t.Unlock()
+}
@@ -0,0 +1,134 @@
+package mcts_test
+
+import (
+	"testing"
+
+	"github.com/chewxy/math32"
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/game/mnk"
+	"github.com/gorgonia/agogo/mcts"
+)
+
+func TestConfig_IsValid_Selection(t *testing.T) {
+	conf := mcts.DefaultConfig(3)
+	conf.PUCT = 2.5
+	if !conf.IsValid() {
+		t.Error("Expected an exploration constant above 1 to be valid")
+	}
+	conf.Selection = mcts.SelectAlphaZero
+	if conf.IsValid() {
+		t.Error("Expected the AlphaZero selection without CBase to be invalid")
+	}
+	conf.CBase, conf.CInit = 19652, 1.25
+	if !conf.IsValid() {
+		t.Error("Expected the AlphaZero selection with CBase and CInit to be valid")
+	}
+	conf.FPUReduction = -1
+	if conf.IsValid() {
+		t.Error("Expected a negative FPU reduction to be invalid")
+	}
+	conf.FPUReduction = 0
+	conf.Selection = mcts.MAXSELECTION
+	if conf.IsValid() {
+		t.Error("Expected an unknown selection to be invalid")
+	}
+}
+
+func TestSelectionPolicies(t *testing.T) {
+	puct := mcts.PUCTPolicy{C: 1}
+	if got := puct.Score(0.5, 0.5, 0, 4); got != 1.5 {
+		t.Errorf("PUCT: expected 1.5. Got %v", got)
+	}
+
+	az := mcts.AlphaZeroPolicy{CBase: 19652, CInit: 1.25}
+	few, many := az.Score(0, 0.5, 0, 100)/10, az.Score(0, 0.5, 0, 1000000)/1000
+	if many <= few {
+		t.Errorf("AlphaZero: expected the exploration constant to grow with the visits. Got %v then %v", few, many)
+	}
+
+	ucb := mcts.UCB1Policy{C: 1.4}
+	if got := ucb.Score(0, 1, 0, 10); !math32.IsInf(got, 1) {
+		t.Errorf("UCB1: expected unvisited children to be selected first. Got %v", got)
+	}
+	if ucb.Score(0.5, 1, 2, 10) <= ucb.Score(0.5, 0, 8, 10) {
+		t.Error("UCB1: expected the less visited child to be explored")
+	}
+}
+
+// priorPolicy always selects the child with the highest prior.
+type priorPolicy struct{}
+
+func (priorPolicy) Score(q, prior float32, visits, parentVisits uint32) float32 { return prior }
+
+func TestMCTS_Selection(t *testing.T) {
+	for _, sel := range []mcts.Selection{mcts.SelectPUCT, mcts.SelectAlphaZero, mcts.SelectUCB1} {
+		g := mnk.TicTacToe()
+		conf := mcts.DefaultConfig(3)
+		conf.Deterministic = true
+		conf.Budget = 200
+		conf.Selection = sel
+		conf.CBase, conf.CInit = 19652, 1.25
+		conf.FPUReduction = 0.25
+		tree := mcts.New(g, conf, hashNN{})
+		if move := tree.Search(Cross); !g.Check(game.PlayerMove{Player: Cross, Single: move}) {
+			t.Errorf("%v: expected a legal move. Got %v", sel, move)
+		}
+	}
+
+	g := mnk.TicTacToe()
+	policy, _ := hashNN{}.Infer(g)
+	var best game.Single
+	for i := 0; i < g.ActionSpace(); i++ {
+		if policy[i] > policy[best] {
+			best = game.Single(i)
+		}
+	}
+	conf := mcts.DefaultConfig(3)
+	conf.Deterministic = true
+	conf.Budget = 200
+	tree := mcts.New(g, conf, hashNN{})
+	tree.SetSelectionPolicy(priorPolicy{})
+	if move := tree.Search(Cross); move != best {
+		t.Errorf("Expected the custom policy to only search the move with the highest prior, %v. Got %v", best, move)
+	}
+}
+
+func TestMCTS_SelectionVisits(t *testing.T) {
+	search := func(sel mcts.Selection, fpuReduction float32) []mcts.ChildResult {
+		g := mnk.TicTacToe()
+		conf := mcts.DefaultConfig(3)
+		conf.Seed = 1337
+		conf.Deterministic = true
+		conf.Budget = 200
+		conf.Selection = sel
+		conf.FPUReduction = fpuReduction
+		tree := mcts.New(g, conf, hashNN{})
+		tree.Search(Cross)
+		return tree.Analysis().Children
+	}
+	visits := func(children []mcts.ChildResult) map[game.Single]uint32 {
+		retVal := make(map[game.Single]uint32)
+		for _, c := range children {
+			retVal[c.Move] = c.Visits
+		}
+		return retVal
+	}
+
+	none, some := visits(search(mcts.SelectPUCT, 0)), visits(search(mcts.SelectPUCT, 5))
+	var differ bool
+	for move, v := range none {
+		if some[move] != v {
+			differ = true
+			break
+		}
+	}
+	if !differ {
+		t.Errorf("Expected the FPU reduction to change the visits of the root. Got %v with and without", none)
+	}
+
+	for _, c := range search(mcts.SelectUCB1, 0) {
+		if c.Visits == 0 {
+			t.Errorf("UCB1: expected every child to be visited. %v was not", c.Move)
+		}
+	}
+}
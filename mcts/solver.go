@@ -0,0 +1,125 @@
+package mcts
+
+import (
+	"sync/atomic"
+
+	"github.com/chewxy/math32"
+	"github.com/gorgonia/agogo/game"
+)
+
+// Proof is the proven result of a node: the result of the game from the node with perfect play.
+// Only searches with Config.Solver prove nodes.
+type Proof uint32
+
+const (
+	Unproven Proof = iota
+	BlackWins
+	WhiteWins
+	ProvenDraw
+)
+
+func (p Proof) String() string {
+	switch p {
+	case Unproven:
+		return "Unproven"
+	case BlackWins:
+		return "BlackWins"
+	case WhiteWins:
+		return "WhiteWins"
+	case ProvenDraw:
+		return "Draw"
+	}
+	return "UNKNOWN PROOF"
+}
+
+// proofOf returns the proof of a game won by winner. A winner that is neither Black nor White is a draw.
+func proofOf(winner game.Player) Proof {
+	switch winner {
+	case Black:
+		return BlackWins
+	case White:
+		return WhiteWins
+	}
+	return ProvenDraw
+}
+
+// Wins returns true if player is proven to win.
+func (p Proof) Wins(player game.Player) bool { return p == proofOf(player) && p != ProvenDraw }
+
+// Loses returns true if player is proven to lose.
+func (p Proof) Loses(player game.Player) bool {
+	return (p == BlackWins && player == White) || (p == WhiteWins && player == Black)
+}
+
+// score returns the score of the proven result for black.
+func (p Proof) score() float32 {
+	switch p {
+	case BlackWins:
+		return 1
+	case WhiteWins:
+		return 0
+	}
+	return 0.5
+}
+
+// Proof returns the proven result of the node.
+func (n *Node) Proof() Proof { return Proof(atomic.LoadUint32(&n.proof)) }
+
+func (n *Node) prove(p Proof) { atomic.StoreUint32(&n.proof, uint32(p)) }
+
+// updateProof proves a node from its children, where player is the player to move. The node is proven when one of the children
+// is a proven win for player, or when every legal move is a child and every child is proven.
+func (t *MCTS) updateProof(n *Node, player game.Player) {
+	if n.Proof() != Unproven || !n.HasChildren() {
+		return
+	}
+	all := n.MinPsaRatio() == 0 // no child was skipped during the expansion
+	var draw bool
+	for _, kid := range t.Children(n.id) {
+		switch p := t.nodeFromNaughty(kid).Proof(); {
+		case p.Wins(player):
+			n.prove(p)
+			return
+		case p == Unproven:
+			all = false
+		case p == ProvenDraw:
+			draw = true
+		}
+	}
+	switch {
+	case !all:
+	case draw:
+		n.prove(ProvenDraw)
+	default:
+		n.prove(proofOf(opponent(player)))
+	}
+}
+
+// provenLoss is the upper bound given to a child that is a proven loss, so that it is only selected when every child is.
+var provenLoss = float32(-math32.MaxFloat32)
+
+// provenMove returns the best move for player among the proven children of the root: a proven win, or, if the first child,
+// which would be played otherwise, is a proven loss, the most visited child that is not. The children may be in any order
+// past the first one, as randomizeChildren shuffles them. It returns false if the proofs do not change the best move.
+func (t *MCTS) provenMove(children []naughty, player game.Player) (game.Single, bool) {
+	for _, kid := range children {
+		child := t.nodeFromNaughty(kid)
+		if child.Proof().Wins(player) {
+			return child.Move(), true
+		}
+	}
+	if len(children) == 0 || !t.nodeFromNaughty(children[0]).Proof().Loses(player) {
+		return Pass, false
+	}
+	var best *Node
+	for _, kid := range children {
+		child := t.nodeFromNaughty(kid)
+		if !child.Proof().Loses(player) && (best == nil || child.Visits() > best.Visits()) {
+			best = child
+		}
+	}
+	if best == nil {
+		return Pass, false
+	}
+	return best.Move(), true
+}
@@ -0,0 +1,66 @@
+package mcts_test
+
+import (
+	"testing"
+
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/game/mnk"
+	"github.com/gorgonia/agogo/mcts"
+)
+
+// position plays the moves on an empty tic tac toe board, starting with Cross.
+func position(moves ...game.Single) *mnk.MNK {
+	g := mnk.TicTacToe()
+	player := Cross
+	for _, m := range moves {
+		g = g.Apply(game.PlayerMove{Player: player, Single: m}).(*mnk.MNK)
+		player = opponent(player)
+	}
+	return g
+}
+
+func solve(g *mnk.MNK, player game.Player, budget int32) (game.Single, mcts.SearchResult) {
+	conf := mcts.DefaultConfig(3)
+	conf.Deterministic = true
+	conf.Budget = budget
+	conf.Solver = true
+	tree := mcts.New(g, conf, hashNN{})
+	move := tree.Search(player)
+	return move, tree.Analysis()
+}
+
+func TestMCTS_Solver_Win(t *testing.T) {
+	// X X .
+	// O O .
+	// . . .
+	move, res := solve(position(0, 3, 1, 4), Cross, 200)
+	if move != 2 {
+		t.Errorf("Expected the winning move 2. Got %v", move)
+	}
+	if res.Proof != mcts.BlackWins {
+		t.Errorf("Expected the root to be a proven win for Cross. Got %v", res.Proof)
+	}
+	for _, c := range res.Children {
+		if c.Move == 2 && c.Proof != mcts.BlackWins {
+			t.Errorf("Expected the winning move to be proven. Got %v", c.Proof)
+		}
+	}
+}
+
+func TestMCTS_Solver_AvoidLoss(t *testing.T) {
+	// X X .
+	// . O X
+	// . . O
+	move, res := solve(position(0, 4, 1, 8, 5), Nought, 500)
+	if move != 2 {
+		t.Errorf("Expected the only move that does not lose, 2. Got %v", move)
+	}
+	for _, c := range res.Children {
+		if c.Move != 2 && !c.Proof.Loses(Nought) {
+			t.Errorf("Expected move %v to be a proven loss. Got %v", c.Move, c.Proof)
+		}
+	}
+	if res.Proof.Loses(Nought) {
+		t.Errorf("Expected the root not to be a proven loss. Got %v", res.Proof)
+	}
+}
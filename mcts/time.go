@@ -0,0 +1,150 @@
+package mcts
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultExpectedMoves = 40                    // default number of moves a player is expected to play in a game
+	minMovesLeft         = 10                    // the time is never allocated as if there were fewer moves left to play
+	defaultOverhead      = 50 * time.Millisecond // default time kept in reserve on every move, for the latency of the communication
+	minMoveTime          = time.Millisecond
+)
+
+// TimeControl is the time control of a game, for one player.
+//
+// After the main time, there may be byoyomi periods of ByoyomiTime for ByoyomiStones moves each (Canadian byoyomi; Japanese byoyomi
+// is one stone per period). Increment is added after every move (Fischer time). A time control where everything is 0 has no time limit.
+type TimeControl struct {
+	MainTime      time.Duration
+	ByoyomiTime   time.Duration
+	ByoyomiStones int
+	Increment     time.Duration
+}
+
+// IsUnlimited returns true if the time control has no time limit.
+func (tc TimeControl) IsUnlimited() bool {
+	return tc.MainTime == 0 && (tc.ByoyomiTime == 0 || tc.ByoyomiStones == 0) && tc.Increment == 0
+}
+
+// byoyomi returns true if the time control has byoyomi periods.
+func (tc TimeControl) byoyomi() bool { return tc.ByoyomiTime > 0 && tc.ByoyomiStones > 0 }
+
+// TimeManager keeps the clock of a player, and allocates the time of every move.
+type TimeManager struct {
+	sync.Mutex
+	TimeControl
+	ExpectedMoves int           // number of moves the player is expected to play in a game. If 0, it is 40
+	Overhead      time.Duration // time kept in reserve on every move. If 0, it is 50ms
+
+	left   time.Duration // time left in the main time or in the current byoyomi period
+	stones int           // stones left to play in the current byoyomi period. 0 in the main time
+}
+
+// NewTimeManager creates a time manager at the start of a game played with the time control.
+func NewTimeManager(tc TimeControl) *TimeManager {
+	retVal := &TimeManager{TimeControl: tc}
+	retVal.Reset()
+	return retVal
+}
+
+// Reset resets the clock to the start of a game.
+func (tm *TimeManager) Reset() {
+	tm.Lock()
+	tm.left, tm.stones = tm.MainTime, 0
+	if tm.left == 0 && tm.byoyomi() {
+		tm.left, tm.stones = tm.ByoyomiTime, tm.ByoyomiStones
+	}
+	tm.Unlock()
+}
+
+// SetTimeLeft sets the clock, as given by a game server: the time left, and the number of stones left to play in it if it is a byoyomi period.
+// If stones is 0, the time left is main time.
+func (tm *TimeManager) SetTimeLeft(left time.Duration, stones int) {
+	tm.Lock()
+	tm.left, tm.stones = left, stones
+	tm.Unlock()
+}
+
+// TimeLeft returns the time left, and the number of stones left to play in it if it is a byoyomi period.
+func (tm *TimeManager) TimeLeft() (left time.Duration, stones int) {
+	tm.Lock()
+	defer tm.Unlock()
+	return tm.left, tm.stones
+}
+
+// Allocate returns the time to think about a move at the given move number of the game. It returns 0 if there is no time limit.
+func (tm *TimeManager) Allocate(moveNumber int) time.Duration {
+	tm.Lock()
+	defer tm.Unlock()
+	if tm.IsUnlimited() {
+		return 0
+	}
+	overhead := tm.Overhead
+	if overhead == 0 {
+		overhead = defaultOverhead
+	}
+
+	var retVal, max time.Duration
+	switch {
+	case tm.stones > 0:
+		// in byoyomi: share the period between the stones left to play in it
+		retVal = tm.left / time.Duration(tm.stones)
+		max = tm.left
+	default:
+		expected := tm.ExpectedMoves
+		if expected == 0 {
+			expected = defaultExpectedMoves
+		}
+		movesLeft := expected - moveNumber/2
+		if movesLeft < minMovesLeft {
+			movesLeft = minMovesLeft
+		}
+		retVal = tm.left/time.Duration(movesLeft) + tm.Increment
+		max = tm.left
+		if tm.byoyomi() {
+			// whatever happens, the time of a move in byoyomi will be there
+			perStone := tm.ByoyomiTime / time.Duration(tm.ByoyomiStones)
+			retVal += perStone
+			max += perStone
+		}
+	}
+	if retVal > max-overhead {
+		retVal = max - overhead
+	}
+	if retVal < minMoveTime {
+		retVal = minMoveTime
+	}
+	return retVal
+}
+
+// Spend updates the clock after a move that took d.
+func (tm *TimeManager) Spend(d time.Duration) {
+	tm.Lock()
+	defer tm.Unlock()
+	if tm.IsUnlimited() {
+		return
+	}
+	tm.left -= d
+	if tm.stones > 0 {
+		tm.stones--
+		if tm.stones == 0 {
+			// a new period starts
+			tm.left, tm.stones = tm.ByoyomiTime, tm.ByoyomiStones
+		}
+		return
+	}
+	tm.left += tm.Increment
+	if tm.left <= 0 && tm.byoyomi() {
+		tm.left, tm.stones = tm.ByoyomiTime+tm.left, tm.ByoyomiStones
+	}
+}
+
+// SetTimeManager makes the search allocate the time of every move with tm, instead of using Timeout.
+// A search with a time manager stops early once the best move cannot be overtaken in the time left. A nil tm removes the time manager.
+func (t *MCTS) SetTimeManager(tm *TimeManager) {
+	t.Lock()
+	t.clock = tm
+	t.Unlock()
+}
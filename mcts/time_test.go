@@ -0,0 +1,74 @@
+package mcts_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gorgonia/agogo/game/mnk"
+	"github.com/gorgonia/agogo/mcts"
+)
+
+func TestTimeManager(t *testing.T) {
+	if d := mcts.NewTimeManager(mcts.TimeControl{}).Allocate(0); d != 0 {
+		t.Errorf("Expected no allocation without a time limit. Got %v", d)
+	}
+
+	// sudden death
+	tm := mcts.NewTimeManager(mcts.TimeControl{MainTime: 60 * time.Second})
+	if d := tm.Allocate(0); d != 1500*time.Millisecond {
+		t.Errorf("Expected the main time to be shared between 40 moves. Got %v", d)
+	}
+	if early, late := tm.Allocate(0), tm.Allocate(100); late <= early {
+		t.Errorf("Expected the allocation to be longer with the same time left later in the game. Got %v, then %v", early, late)
+	}
+	tm.SetTimeLeft(100*time.Millisecond, 0)
+	if d := tm.Allocate(0); d > 50*time.Millisecond {
+		t.Errorf("Expected the allocation to keep the overhead in reserve. Got %v", d)
+	}
+
+	// increment
+	tm = mcts.NewTimeManager(mcts.TimeControl{MainTime: 40 * time.Second, Increment: 2 * time.Second})
+	if d := tm.Allocate(0); d != 3*time.Second {
+		t.Errorf("Expected the increment to be added. Got %v", d)
+	}
+	tm.Spend(time.Second)
+	if left, _ := tm.TimeLeft(); left != 41*time.Second {
+		t.Errorf("Expected 41s left after the increment. Got %v", left)
+	}
+
+	// byoyomi
+	tc := mcts.TimeControl{MainTime: time.Second, ByoyomiTime: 30 * time.Second, ByoyomiStones: 5}
+	tm = mcts.NewTimeManager(tc)
+	tm.Spend(2 * time.Second)
+	if left, stones := tm.TimeLeft(); left != 29*time.Second || stones != 5 {
+		t.Errorf("Expected to be in byoyomi with 29s for 5 stones. Got %v for %d", left, stones)
+	}
+	tm.SetTimeLeft(30*time.Second, 5)
+	if d := tm.Allocate(100); d != 6*time.Second {
+		t.Errorf("Expected the byoyomi period to be shared between its stones. Got %v", d)
+	}
+	for i := 0; i < 5; i++ {
+		tm.Spend(time.Second)
+	}
+	if left, stones := tm.TimeLeft(); left != 30*time.Second || stones != 5 {
+		t.Errorf("Expected a new byoyomi period. Got %v for %d", left, stones)
+	}
+}
+
+func TestMCTS_SetTimeManager(t *testing.T) {
+	g := mnk.TicTacToe()
+	conf := mcts.DefaultConfig(3)
+	conf.Timeout = time.Minute
+	tree := mcts.New(g, conf, hashNN{})
+	tm := mcts.NewTimeManager(mcts.TimeControl{MainTime: 2 * time.Second})
+	tree.SetTimeManager(tm)
+
+	start := time.Now()
+	tree.Search(Cross)
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Expected the search to take the allocated time, not the timeout. Took %v", elapsed)
+	}
+	if left, _ := tm.TimeLeft(); left >= 2*time.Second {
+		t.Errorf("Expected the search to spend time. %v left", left)
+	}
+}
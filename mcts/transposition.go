@@ -0,0 +1,178 @@
+package mcts
+
+import (
+	"sync"
+	"unsafe"
+
+	"github.com/gorgonia/agogo/game"
+)
+
+// transposition is an entry of the transposition table: the evaluation of a position by the neural network, and the
+// statistics of every node that reached the position.
+type transposition struct {
+	hash   game.Zobrist
+	player game.Player
+	check  uint64 // a second hash of the board, used to check for collisions of the Zobrist hashes
+
+	policy []float32
+	value  float32 // value from the point of view of player, as given by the neural network
+
+	visits      uint32
+	blackScores float32
+
+	id uint64 // unique ID of the entry, so that a replaced entry is not updated
+}
+
+// ttRef refers to an entry of the transposition table.
+type ttRef struct {
+	index int
+	id    uint64
+}
+
+// transpositions is a transposition table. It has a fixed number of entries, which fit in its memory budget,
+// and a newer position replaces an older one with the same index.
+type transpositions struct {
+	sync.Mutex
+	entries      []transposition
+	hits, misses int
+	ids          uint64
+}
+
+// newTranspositions creates a transposition table that takes about budget bytes, for a game with the given action space.
+// It returns nil if not a single entry fits.
+func newTranspositions(budget, actionSpace int) *transpositions {
+	size := budget / transpositionSize(actionSpace)
+	if size <= 0 {
+		return nil
+	}
+	return &transpositions{entries: make([]transposition, size)}
+}
+
+// transpositionSize returns the estimated size of an entry in bytes: the entry itself and its policy.
+func transpositionSize(actionSpace int) int {
+	return int(unsafe.Sizeof(transposition{})) + (actionSpace+1)*int(unsafe.Sizeof(float32(0)))
+}
+
+// boardCheck returns the FNV-1a hash of the board of the state.
+func boardCheck(state game.State) uint64 {
+	const offset, prime = 14695981039346656037, 1099511628211
+	retVal := uint64(offset)
+	for _, c := range state.Board() {
+		retVal ^= uint64(uint32(c))
+		retVal *= prime
+	}
+	return retVal
+}
+
+func (tt *transpositions) index(hash game.Zobrist) int {
+	return int(uint32(hash) % uint32(len(tt.entries)))
+}
+
+// find returns the entry of the state, if any. It must be called with the lock held.
+func (tt *transpositions) find(state game.State) *transposition {
+	hash := state.Hash()
+	e := &tt.entries[tt.index(hash)]
+	if e.id == 0 || e.hash != hash || e.player != state.ToMove() || e.check != boardCheck(state) {
+		return nil
+	}
+	return e
+}
+
+// ref returns a reference to the entry of the state, if any.
+func (tt *transpositions) ref(state game.State) (ttRef, bool) {
+	tt.Lock()
+	defer tt.Unlock()
+	e := tt.find(state)
+	if e == nil {
+		return ttRef{}, false
+	}
+	return ttRef{index: tt.index(e.hash), id: e.id}, true
+}
+
+// evaluation returns the cached evaluation of the state by the neural network.
+func (tt *transpositions) evaluation(state game.State) (policy []float32, value float32, ok bool) {
+	tt.Lock()
+	defer tt.Unlock()
+	e := tt.find(state)
+	if e == nil {
+		tt.misses++
+		return nil, 0, false
+	}
+	tt.hits++
+	return e.policy, e.value, true
+}
+
+// store stores the evaluation of the state by the neural network, replacing whatever was in its slot.
+func (tt *transpositions) store(state game.State, policy []float32, value float32) {
+	hash := state.Hash()
+	check := boardCheck(state)
+	tt.Lock()
+	tt.ids++
+	tt.entries[tt.index(hash)] = transposition{
+		hash:   hash,
+		player: state.ToMove(),
+		check:  check,
+		policy: policy,
+		value:  value,
+		id:     tt.ids,
+	}
+	tt.Unlock()
+}
+
+// score returns the mean of the results of all the nodes that reached the state, as a score for black.
+func (tt *transpositions) score(state game.State) (score float32, ok bool) {
+	tt.Lock()
+	defer tt.Unlock()
+	e := tt.find(state)
+	if e == nil || e.visits == 0 {
+		return 0, false
+	}
+	return e.blackScores / float32(e.visits), true
+}
+
+// update adds the result of a playout through the position of the referred entry, unless the entry has been replaced since.
+func (tt *transpositions) update(ref ttRef, score float32) {
+	tt.Lock()
+	if e := &tt.entries[ref.index]; e.id == ref.id {
+		e.visits++
+		e.blackScores += score
+	}
+	tt.Unlock()
+}
+
+func (tt *transpositions) reset() {
+	tt.Lock()
+	for i := range tt.entries {
+		tt.entries[i] = transposition{}
+	}
+	tt.hits, tt.misses = 0, 0
+	tt.Unlock()
+}
+
+// infer evaluates the state with the neural network, unless the transposition table or the evaluation cache already has the evaluation.
+func (t *MCTS) infer(state game.State) (policy []float32, value float32) {
+	if t.tt != nil {
+		if policy, value, ok := t.tt.evaluation(state); ok {
+			return policy, value
+		}
+	}
+	var ok bool
+	if policy, value, ok = t.cache.Get(state); !ok {
+		policy, value = t.nn.Infer(state)
+		t.cache.Put(state, policy, value)
+	}
+	if t.tt != nil {
+		t.tt.store(state, policy, value)
+	}
+	return policy, value
+}
+
+// Transpositions returns the number of hits and misses of the transposition table.
+func (t *MCTS) Transpositions() (hits, misses int) {
+	if t.tt == nil {
+		return 0, 0
+	}
+	t.tt.Lock()
+	defer t.tt.Unlock()
+	return t.tt.hits, t.tt.misses
+}
@@ -0,0 +1,58 @@
+package mcts_test
+
+import (
+	"testing"
+
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/game/mnk"
+	"github.com/gorgonia/agogo/mcts"
+)
+
+// countingNN counts the calls to the inferencer.
+type countingNN struct {
+	hashNN
+	calls int
+}
+
+func (nn *countingNN) Infer(state game.State) (policy []float32, value float32) {
+	nn.calls++
+	return nn.hashNN.Infer(state)
+}
+
+func TestMCTS_Transpositions(t *testing.T) {
+	search := func(budget int) (*mcts.MCTS, int) {
+		g := mnk.TicTacToe()
+		conf := mcts.DefaultConfig(3)
+		conf.Seed = 1337
+		conf.Deterministic = true
+		conf.Budget = 1000
+		conf.TranspositionMemory = budget
+		nn := &countingNN{}
+		tree := mcts.New(g, conf, nn)
+		move := tree.Search(Cross)
+		if !g.Check(game.PlayerMove{Player: Cross, Single: move}) {
+			t.Fatalf("Expected a legal move. Got %v", move)
+		}
+		return tree, nn.calls
+	}
+
+	tree, without := search(0)
+	if hits, misses := tree.Transpositions(); hits != 0 || misses != 0 {
+		t.Errorf("Expected no transposition table. Got %d hits and %d misses", hits, misses)
+	}
+
+	tree, with := search(1 << 20)
+	hits, misses := tree.Transpositions()
+	if hits == 0 {
+		t.Errorf("Expected transpositions to be found")
+	}
+	if misses != with {
+		t.Errorf("Expected every miss to call the inferencer. Got %d misses and %d calls", misses, with)
+	}
+	if with >= without {
+		t.Errorf("Expected the transposition table to save calls to the inferencer. Got %d calls with it, %d without", with, without)
+	}
+
+	// a tiny table has many collisions, which must not be mistaken for transpositions
+	search(256)
+}
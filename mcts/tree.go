@@ -1,11 +1,13 @@
 package mcts
 
 import (
+	"context"
 	"math/rand"
 	"runtime"
 	"sync"
 	"sync/atomic"
 	"time"
+	"unsafe"
 
 	"github.com/chewxy/math32"
 	"github.com/gorgonia/agogo/game"
@@ -13,10 +15,15 @@ import (
 
 // Config is the structure to configure the MCTS multitree (poorly named Tree)
 type Config struct {
-	// PUCT is the proportion of polynomial upper confidence trees to keep. Between 1 and 0
+	// PUCT is the exploration constant of the PUCT and UCB1 selections. It must be positive
 	PUCT    float32
 	Timeout time.Duration
 
+	// Selection is the tree policy. It may be replaced by a custom one with SetSelectionPolicy
+	Selection    Selection
+	CBase, CInit float32 // growth of the exploration of the AlphaZero selection: log((1 + N + CBase) / CBase) + CInit
+	FPUReduction float32 // first play urgency: unvisited children are valued as their parent's NN value minus FPUReduction * sqrt(sum of the priors of the visited children)
+
 	// M, N represents the height and width.
 	M, N              int
 	RandomCount       int   // if the move number is less than this, we should randomize
@@ -26,6 +33,36 @@ type Config struct {
 	DumbPass          bool
 	ResignPercentage  float32
 	PassPreference    PassPreference
+
+	// Dirichlet noise on the priors of the root's children. It is only added when enabled with SetNoise, i.e. in self play.
+	DirichletAlpha   float32 // concentration of the noise. AlphaZero used 0.3 for chess and 0.03 for Go: roughly 10 / number of legal moves
+	DirichletEpsilon float32 // fraction of noise mixed into the priors. 0 means no noise
+
+	Seed int64 // seed of the random number generator. If 0, it is seeded from the clock
+
+	// Deterministic searches in a single goroutine for exactly Budget iterations, ignoring Timeout.
+	// Together with a Seed, searching the same position gives the same result every time.
+	Deterministic bool
+
+	// EarlyStop stops a search once the most visited move cannot be overtaken in the rest of the Budget or of the Timeout.
+	EarlyStop bool
+
+	PVDepth int // maximum length of the principal variation of a SearchResult. If 0, it is M*N
+
+	// TranspositionMemory is the memory budget of the transposition table in bytes. The table shares the evaluations and results of
+	// positions reached through different orders of moves. An entry takes about 64 bytes plus 4 bytes per action. If 0, there is no table.
+	TranspositionMemory int
+
+	// MaxNodes is the maximum number of nodes of the tree. If 0, it is MAXTREESIZE. As the tree fills up, fewer children are expanded,
+	// and once it is full, leaves are evaluated without being expanded. A node may still be expanded with all its children when the
+	// tree is just short of full, so the tree may slightly exceed MaxNodes.
+	MaxNodes int
+
+	// Solver detects the ends of games during the search, and proves the nodes whose result is certain (MCTS-Solver).
+	// Proven wins are always played, and proven losses avoided.
+	Solver bool
+
+	PolicyTemperature float32 // temperature of the policy targets recorded in self play. See SearchResult.Policy
 }
 
 func DefaultConfig(boardSize int) Config {
@@ -41,7 +78,22 @@ func DefaultConfig(boardSize int) Config {
 }
 
 func (c Config) IsValid() bool {
-	return c.PUCT > 0 && c.PUCT <= 1
+	if c.MaxNodes < 0 {
+		return false
+	}
+	switch c.Selection {
+	case SelectPUCT, SelectUCB1:
+		if c.PUCT <= 0 {
+			return false
+		}
+	case SelectAlphaZero:
+		if c.CBase <= 0 || c.CInit < 0 {
+			return false
+		}
+	default:
+		return false
+	}
+	return c.FPUReduction >= 0 && (!c.Deterministic || c.Budget > 0)
 }
 
 // sa is a state-action tuple, used for storing results
@@ -54,8 +106,14 @@ type sa struct {
 type MCTS struct {
 	sync.RWMutex
 	Config
-	nn   Inferencer
-	rand *rand.Rand
+	nn    Inferencer
+	rand  *rand.Rand
+	noise bool // add Dirichlet noise to the root
+	tt    *transpositions
+	cache *EvalCache
+
+	policy SelectionPolicy
+	clock  *TimeManager
 
 	// memory related fields
 	nodes []Node
@@ -69,8 +127,14 @@ type MCTS struct {
 	// global searchState
 	searchState
 	playouts, nc int32 // atomic pls
+	iter         int32 // iterations of the current search
 	running      atomic.Value
 
+	// background search. See Start and Stop
+	cancel   context.CancelFunc
+	done     chan game.Single
+	lastMove game.Single // move chosen by the last search
+
 	// global policy values - useful for building policy vectors
 	cachedPolicies map[sa]float32
 
@@ -81,13 +145,17 @@ func New(game game.State, conf Config, nn Inferencer) *MCTS {
 	retVal := &MCTS{
 		Config: conf,
 		nn:     nn,
-		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		rand:   NewRand(conf.Seed),
+		tt:     newTranspositions(conf.TranspositionMemory, game.ActionSpace()),
+		policy: conf.selectionPolicy(),
 
 		nodes: make([]Node, 0, 12288),
 		// children: make(map[naughty][]naughty),
 		children:  make([][]naughty, 0, 12288),
 		childLock: make([]sync.Mutex, 0, 12288),
 
+		lastMove: Pass,
+
 		searchState: searchState{
 			root:    nilNode,
 			current: game,
@@ -102,12 +170,19 @@ func New(game game.State, conf Config, nn Inferencer) *MCTS {
 	return retVal
 }
 
+// NewRand creates a new random number generator from seed. If seed is 0, it is seeded from the clock.
+func NewRand(seed int64) *rand.Rand {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
 // New creates a new node
 func (t *MCTS) New(move game.Single, score, value float32) (retVal naughty) {
 	n := t.alloc()
 	N := t.nodeFromNaughty(n)
 	atomic.StoreInt32(&N.move, int32(move))
-	atomic.StoreUint32(&N.visits, 1)
 	atomic.StoreUint32(&N.status, uint32(Active))
 	atomic.StoreUint32(&N.score, math32.Float32bits(score))
 	atomic.StoreUint32(&N.value, math32.Float32bits(value))
@@ -123,8 +198,37 @@ func (t *MCTS) SetGame(g game.State) {
 	t.Unlock()
 }
 
+// Nodes returns the number of nodes allocated, including the free ones.
 func (t *MCTS) Nodes() int { return len(t.nodes) }
 
+// NodeCount returns the number of nodes in use.
+func (t *MCTS) NodeCount() int { return int(atomic.LoadInt32(&t.nc)) }
+
+// MemoryUsage returns an estimate of the memory used by the tree, in bytes.
+func (t *MCTS) MemoryUsage() int64 {
+	t.RLock()
+	defer t.RUnlock()
+	retVal := int64(cap(t.nodes)) * int64(unsafe.Sizeof(Node{}))
+	retVal += int64(cap(t.childLock)) * int64(unsafe.Sizeof(sync.Mutex{}))
+	retVal += int64(cap(t.children)) * int64(unsafe.Sizeof([]naughty{}))
+	for _, c := range t.children {
+		retVal += int64(cap(c)) * int64(unsafe.Sizeof(naughty(0)))
+	}
+	retVal += int64(cap(t.freelist)+cap(t.freeables)) * int64(unsafe.Sizeof(naughty(0)))
+	return retVal
+}
+
+// maxNodes returns the maximum number of nodes of the tree.
+func (t *MCTS) maxNodes() int32 {
+	if t.MaxNodes > 0 {
+		return int32(t.MaxNodes)
+	}
+	return MAXTREESIZE
+}
+
+// Policies returns how often every move has been chosen in the position g.
+//
+// Deprecated: the counts of the chosen moves are a poor policy target. Use the Policy of the SearchResult instead.
 func (t *MCTS) Policies(g game.State) []float32 {
 	hash := g.Hash()
 	var sum float32
@@ -143,6 +247,7 @@ func (t *MCTS) Policies(g game.State) []float32 {
 
 // alloc tries to get a node from the free list. If none is found a new node is allocated into the master arena
 func (t *MCTS) alloc() naughty {
+	atomic.AddInt32(&t.nc, 1)
 	t.Lock()
 	l := len(t.freelist)
 	if l == 0 {
@@ -177,23 +282,31 @@ func (t *MCTS) free(n naughty) {
 	t.freelist = append(t.freelist, n)
 	N := &t.nodes[int(n)]
 	N.reset()
+	atomic.AddInt32(&t.nc, -1)
 }
 
-// cleanup cleans up the graph (WORK IN PROGRESS)
+// freeSubtree marks a node and all its descendants to be freed.
+func (t *MCTS) freeSubtree(root naughty) {
+	t.nodeFromNaughty(root).Invalidate()
+	t.freeables = append(t.freeables, root)
+	t.cleanChildren(root)
+}
+
+// cleanup marks the nodes that cannot be reached anymore once the root moves from oldRoot to its child newRoot to be freed:
+// oldRoot, and all its other children and their descendants.
 func (t *MCTS) cleanup(oldRoot, newRoot naughty) {
 	children := t.Children(oldRoot)
 	// we aint going down other paths, those nodes can be freed
 	for _, kid := range children {
 		if kid != newRoot {
-			t.nodeFromNaughty(kid).Invalidate()
-			t.freeables = append(t.freeables, kid)
-			t.cleanChildren(kid)
+			t.freeSubtree(kid)
 		}
 	}
 	t.Lock()
-	t.children[oldRoot] = t.children[oldRoot][:1]
-	t.children[oldRoot][0] = newRoot
+	t.children[oldRoot] = t.children[oldRoot][:0]
 	t.Unlock()
+	t.nodeFromNaughty(oldRoot).Invalidate()
+	t.freeables = append(t.freeables, oldRoot)
 }
 
 func (t *MCTS) cleanChildren(root naughty) {
@@ -252,16 +365,9 @@ func (t *MCTS) Reset() {
 
 	t.freelist = t.freelist[:0]
 	t.freeables = t.freeables[:0]
+	// every node is kept allocated, for reuse
 	for i := range t.nodes {
-		t.nodes[i].move = -1
-		t.nodes[i].visits = 0
-		t.nodes[i].status = 0
-		t.nodes[i].blackScores = 0
-		t.nodes[i].virtualLoss = 0
-		t.nodes[i].minPSARatioChildren = 0
-		t.nodes[i].score = 0
-		t.nodes[i].value = 0
-
+		t.nodes[i].reset()
 		t.freelist = append(t.freelist, t.nodes[i].id)
 	}
 
@@ -270,7 +376,12 @@ func (t *MCTS) Reset() {
 	}
 
 	t.playouts = 0
-	t.nodes = t.nodes[:0]
+	t.nc = 0
+	t.searchState.root = nilNode
+	t.searchState.prev = nil
+	if t.tt != nil {
+		t.tt.reset()
+	}
 	t.cachedPolicies = make(map[sa]float32)
 	runtime.GC()
 }
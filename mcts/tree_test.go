@@ -0,0 +1,117 @@
+package mcts
+
+import (
+	"testing"
+
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/game/mnk"
+)
+
+// uniformNN gives the same probability to every move, and an even value to every position.
+type uniformNN struct{}
+
+func (uniformNN) Infer(state game.State) (policy []float32, value float32) {
+	policy = make([]float32, state.ActionSpace()+1)
+	for i := range policy {
+		policy[i] = 1 / float32(len(policy))
+	}
+	return policy, 0.5
+}
+
+// checkNodes checks that the nodes in use are exactly the nodes that can be reached from the root.
+func checkNodes(t *testing.T, tree *MCTS, move int) {
+	reachable := tree.nodeFromNaughty(tree.root).countChildren() + 1
+	if tree.NodeCount() != reachable {
+		t.Errorf("Move %d: expected %d nodes in use. Got %d", move, reachable, tree.NodeCount())
+	}
+	if tree.NodeCount()+len(tree.freelist) != tree.Nodes() {
+		t.Errorf("Move %d: expected every node to be in use or free. %d in use, %d free, %d allocated", move, tree.NodeCount(), len(tree.freelist), tree.Nodes())
+	}
+}
+
+func TestMCTS_MaxNodes(t *testing.T) {
+	g := mnk.New(5, 5, 4)
+	conf := DefaultConfig(5)
+	conf.Deterministic = true
+	conf.Budget = 300
+	conf.MaxNodes = 100
+	tree := New(g, conf, uniformNN{})
+
+	player := Black
+	for move := 0; ; move++ {
+		if ended, _ := g.Ended(); ended {
+			break
+		}
+		best := tree.Search(player)
+		if !g.Check(game.PlayerMove{Player: player, Single: best}) {
+			t.Fatalf("Move %d: expected a legal move. Got %v", move, best)
+		}
+		checkNodes(t, tree, move)
+		// a full tree may still expand a node with all its children
+		if max := conf.MaxNodes + g.ActionSpace() + 1; tree.NodeCount() > max {
+			t.Errorf("Move %d: expected at most %d nodes. Got %d", move, max, tree.NodeCount())
+		}
+		g = g.Apply(game.PlayerMove{Player: player, Single: best}).(*mnk.MNK)
+		tree.SetGame(g)
+		player = opponent(player)
+	}
+	if tree.MemoryUsage() <= 0 {
+		t.Error("Expected the memory usage to be estimated")
+	}
+}
+
+func TestMCTS_freeUnreachable(t *testing.T) {
+	g := mnk.TicTacToe()
+	g = g.Apply(game.PlayerMove{Player: Black, Single: 0}).(*mnk.MNK)
+	conf := DefaultConfig(3)
+	conf.Deterministic = true
+	conf.Budget = 300
+	tree := New(g, conf, uniformNN{})
+	tree.Search(White)
+	before := tree.NodeCount()
+
+	// a position that cannot be reached from the searched one: the whole tree has to be freed
+	other := mnk.TicTacToe()
+	other = other.Apply(game.PlayerMove{Player: Black, Single: 8}).(*mnk.MNK)
+	tree.SetGame(other)
+	tree.Search(White)
+	checkNodes(t, tree, 1)
+	if tree.Nodes() > before+g.ActionSpace()+1 {
+		t.Errorf("Expected the nodes of the old tree to be reused. %d nodes allocated, %d in use before", tree.Nodes(), before)
+	}
+
+	tree.Reset()
+	if tree.NodeCount() != 0 {
+		t.Errorf("Expected no node in use after a reset. Got %d", tree.NodeCount())
+	}
+	tree.Search(White)
+	checkNodes(t, tree, 2)
+}
+
+func TestMCTS_provenMove(t *testing.T) {
+	tree := New(mnk.TicTacToe(), DefaultConfig(3), nil)
+	// the children are not sorted, as after randomizeChildren. The first one is a proven loss for black
+	var children []naughty
+	for i, c := range []struct {
+		visits uint32
+		proof  Proof
+	}{{10, WhiteWins}, {1, Unproven}, {5, ProvenDraw}, {20, WhiteWins}, {3, Unproven}} {
+		kid := tree.New(game.Single(i), 0.2, 0)
+		child := tree.nodeFromNaughty(kid)
+		child.visits = c.visits
+		child.prove(c.proof)
+		children = append(children, kid)
+	}
+	move, ok := tree.provenMove(children, Black)
+	if !ok || move != 2 {
+		t.Errorf("Expected the most visited child that does not lose. Got %v, %t", move, ok)
+	}
+
+	tree.nodeFromNaughty(children[4]).prove(BlackWins)
+	if move, ok = tree.provenMove(children, Black); !ok || move != 4 {
+		t.Errorf("Expected the proven win. Got %v, %t", move, ok)
+	}
+	if _, ok = tree.provenMove(children[1:3], Black); ok {
+		t.Errorf("Expected no change when the first child is not a proven loss")
+	}
+}
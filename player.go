@@ -0,0 +1,244 @@
+package agogo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/mcts"
+	"github.com/pkg/errors"
+)
+
+// Player is anything that can play a game in an arena: an Agent, a baseline that does not learn, a human or an external engine.
+//
+// For every game, the arena calls Start, then Move whenever it is the player's turn and Played after every move of either player,
+// and finally End.
+type Player interface {
+	Name() string
+
+	// Start starts a new game of g, where the player plays colour.
+	Start(g game.State, colour game.Player) error
+
+	// Move returns the move of the player in g. The player must not modify g.
+	Move(g game.State) (game.Single, error)
+
+	// Played is called after every move of the game, including the player's own.
+	Played(m game.PlayerMove) error
+
+	// End ends the game, with its record.
+	End(rec GameRecord) error
+}
+
+// analyser is a Player that searches for its moves. The search of every move is kept in the records of the games.
+type analyser interface {
+	Analysis() mcts.SearchResult
+}
+
+// Name returns the name of the agent.
+func (a *Agent) Name() string { return a.name }
+
+// Start starts a new game, where the agent plays colour.
+func (a *Agent) Start(g game.State, colour game.Player) error {
+	a.Player = colour
+	return nil
+}
+
+// Move searches g for the best move.
+func (a *Agent) Move(g game.State) (game.Single, error) { return a.Search(g), nil }
+
+// Played does nothing: the search tree follows the game by itself.
+func (a *Agent) Played(m game.PlayerMove) error { return nil }
+
+// End updates the statistics of the agent with the result of the game, and resets its search tree.
+func (a *Agent) End(rec GameRecord) error {
+	a.Lock()
+	switch rec.Outcome(a.Player) {
+	case 1:
+		a.Wins++
+	case -1:
+		a.Loss++
+	default:
+		a.Draw++
+	}
+	a.Unlock()
+	a.MCTS.Reset()
+	return nil
+}
+
+// Analysis returns the analysis of the last search of the agent.
+func (a *Agent) Analysis() mcts.SearchResult { return a.MCTS.Analysis() }
+
+// legalMoves returns the legal moves of player in g, except passing and resigning.
+func legalMoves(g game.State, player game.Player) []game.Single {
+	var retVal []game.Single
+	for i := 0; i < g.ActionSpace(); i++ {
+		if g.Check(game.PlayerMove{Player: player, Single: game.Single(i)}) {
+			retVal = append(retVal, game.Single(i))
+		}
+	}
+	return retVal
+}
+
+// RandomPlayer plays uniformly random legal moves. It only passes when it has no other legal move.
+type RandomPlayer struct {
+	name string
+	r    *rand.Rand
+}
+
+// NewRandomPlayer creates a random player. If seed is 0, it is seeded from the clock.
+func NewRandomPlayer(name string, seed int64) *RandomPlayer {
+	return &RandomPlayer{name: name, r: mcts.NewRand(seed)}
+}
+
+func (p *RandomPlayer) Name() string                                 { return p.name }
+func (p *RandomPlayer) Start(g game.State, colour game.Player) error { return nil }
+func (p *RandomPlayer) Played(m game.PlayerMove) error               { return nil }
+func (p *RandomPlayer) End(rec GameRecord) error                     { return nil }
+func (p *RandomPlayer) Move(g game.State) (game.Single, error) {
+	moves := legalMoves(g, g.ToMove())
+	if len(moves) == 0 {
+		return mcts.Pass, nil
+	}
+	return moves[p.r.Intn(len(moves))], nil
+}
+
+// RolloutPlayer searches with MCTS like an Agent, but evaluates positions by playing them out at random instead of with a neural network.
+// It is meant to be searched with mcts.SelectUCB1, which ignores the uniform priors.
+type RolloutPlayer struct {
+	MCTS *mcts.MCTS
+
+	name   string
+	player game.Player
+}
+
+// NewRolloutPlayer creates a rollout player that plays g, and searches with conf. Every position is evaluated by one random playout.
+func NewRolloutPlayer(name string, g game.State, conf mcts.Config) *RolloutPlayer {
+	return &RolloutPlayer{
+		MCTS: mcts.New(g, conf, mcts.NewRollout(1, nil, conf.Seed)),
+		name: name,
+	}
+}
+
+func (p *RolloutPlayer) Name() string { return p.name }
+
+func (p *RolloutPlayer) Start(g game.State, colour game.Player) error {
+	p.player = colour
+	return nil
+}
+
+func (p *RolloutPlayer) Move(g game.State) (game.Single, error) {
+	p.MCTS.SetGame(g)
+	return p.MCTS.Search(p.player), nil
+}
+
+func (p *RolloutPlayer) Played(m game.PlayerMove) error { return nil }
+
+func (p *RolloutPlayer) End(rec GameRecord) error {
+	p.MCTS.Reset()
+	return nil
+}
+
+// Analysis returns the analysis of the last search of the player.
+func (p *RolloutPlayer) Analysis() mcts.SearchResult { return p.MCTS.Analysis() }
+
+// HumanPlayer is a human that plays in a terminal, or anything else that reads and writes text.
+//
+// The human is shown the board, and enters a move as the index of the point, as a row and a column separated by a space,
+// or as "pass" or "resign".
+type HumanPlayer struct {
+	name   string
+	in     *bufio.Scanner
+	out    io.Writer
+	player game.Player
+}
+
+// NewHumanPlayer creates a human player that reads the moves from in, and writes the game to out. For a human at a terminal,
+// they are os.Stdin and os.Stdout.
+func NewHumanPlayer(name string, in io.Reader, out io.Writer) *HumanPlayer {
+	return &HumanPlayer{
+		name: name,
+		in:   bufio.NewScanner(in),
+		out:  out,
+	}
+}
+
+func (p *HumanPlayer) Name() string { return p.name }
+
+func (p *HumanPlayer) Start(g game.State, colour game.Player) error {
+	p.player = colour
+	_, err := fmt.Fprintf(p.out, "New game. %v plays %v\n", p.name, colour)
+	return err
+}
+
+// Move asks for a move until a legal one is entered.
+func (p *HumanPlayer) Move(g game.State) (game.Single, error) {
+	for {
+		fmt.Fprintf(p.out, "%s\n%v to move: ", g, p.player)
+		if !p.in.Scan() {
+			if err := p.in.Err(); err != nil {
+				return mcts.Pass, errors.WithStack(err)
+			}
+			return mcts.Pass, errors.Errorf("%v has no more moves to read", p.name)
+		}
+		move, err := parseMove(p.in.Text(), g)
+		if err != nil {
+			fmt.Fprintln(p.out, err)
+			continue
+		}
+		if move.IsResignation() || g.Check(game.PlayerMove{Player: p.player, Single: move}) {
+			return move, nil
+		}
+		fmt.Fprintf(p.out, "Illegal move %v\n", move)
+	}
+}
+
+func (p *HumanPlayer) Played(m game.PlayerMove) error {
+	if m.Player == p.player {
+		return nil
+	}
+	_, err := fmt.Fprintf(p.out, "%v played %v\n", m.Player, m.Single)
+	return err
+}
+
+func (p *HumanPlayer) End(rec GameRecord) error {
+	_, err := fmt.Fprintf(p.out, "Winner: %v (%v)\n", rec.Winner, rec.Termination)
+	return err
+}
+
+// parseMove parses a move entered by a human in g.
+func parseMove(s string, g game.State) (game.Single, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	var i int
+	var err error
+	switch {
+	case len(fields) == 1 && fields[0] == "pass":
+		return mcts.Pass, nil
+	case len(fields) == 1 && fields[0] == "resign":
+		return mcts.Resign, nil
+	case len(fields) == 1:
+		i, err = strconv.Atoi(fields[0])
+	case len(fields) == 2:
+		var row, col int
+		if row, err = strconv.Atoi(fields[0]); err == nil {
+			col, err = strconv.Atoi(fields[1])
+		}
+		_, n := g.BoardSize()
+		if col < 0 || col >= n {
+			return mcts.Pass, errors.Errorf("Column %d is off the board", col)
+		}
+		i = row*n + col
+	default:
+		return mcts.Pass, errors.Errorf("Unable to parse move %q", s)
+	}
+	if err != nil {
+		return mcts.Pass, errors.Errorf("Unable to parse move %q", s)
+	}
+	if i < 0 || i >= g.ActionSpace() {
+		return mcts.Pass, errors.Errorf("Move %q is off the board", s)
+	}
+	return game.Single(i), nil
+}
@@ -0,0 +1,73 @@
+package agogo
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/game/mnk"
+	"github.com/gorgonia/agogo/mcts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArena_Match(t *testing.T) {
+	assert := assert.New(t)
+	ar := &Arena{game: mnk.TicTacToe(), r: mcts.NewRand(1337)}
+	ar.logger = log.New(&ar.buf, "", log.Ltime)
+
+	var out bytes.Buffer
+	human := NewHumanPlayer("human", strings.NewReader("1 1\nfoo\n4\n0\n1\n2\n3\n5\n6\n7\n8\n"), &out)
+	rec, err := ar.Match(human, NewRandomPlayer("random", 1337), nil)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	assert.Equal("human", rec.Black)
+	assert.Equal("random", rec.White)
+	assert.Equal(game.Single(4), rec.Moves[0].Single, "1 1 is the centre of the board")
+	assert.Contains(out.String(), "Unable to parse move")
+	assert.Contains(out.String(), "Illegal move", "The centre is taken after the first move")
+	assert.Contains(out.String(), "Winner")
+	ended, winner := ar.game.Ended()
+	assert.True(ended)
+	assert.Equal(winner, rec.Winner)
+
+	ar.game.Reset()
+	human = NewHumanPlayer("human", strings.NewReader("resign\n"), &out)
+	rec, err = ar.Match(NewRandomPlayer("random", 1337), human, nil)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	assert.Equal(Resignation, rec.Termination)
+	assert.Equal(game.Player(game.Black), rec.Winner)
+
+	ar.game.Reset()
+	human = NewHumanPlayer("human", strings.NewReader(""), &out)
+	_, err = ar.Match(human, NewRandomPlayer("random", 1337), nil)
+	assert.Error(err, "A human that has no more moves should fail to play")
+}
+
+func TestRolloutPlayer(t *testing.T) {
+	g := mnk.TicTacToe()
+	g.SetToMove(game.Player(game.Black))
+	for _, m := range []game.Single{0, 3, 1, 4} {
+		g = g.Apply(game.PlayerMove{Player: g.ToMove(), Single: m}).(*mnk.MNK)
+	}
+	conf := mcts.DefaultConfig(3)
+	conf.Selection = mcts.SelectUCB1
+	conf.PUCT = 1.4
+	conf.Budget = 500
+	conf.Deterministic = true
+	conf.Seed = 1337
+	p := NewRolloutPlayer("rollout", g, conf)
+	if err := p.Start(g, g.ToMove()); err != nil {
+		t.Fatal(err)
+	}
+	move, err := p.Move(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, game.Single(2), move, "The rollout player should complete the top row")
+	assert.NotZero(t, p.Analysis().Playouts)
+}
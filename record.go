@@ -0,0 +1,136 @@
+package agogo
+
+import (
+	"github.com/chewxy/math32"
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/mcts"
+)
+
+// Termination is the reason a game ended.
+type Termination int
+
+const (
+	GameOver    Termination = iota // the rules of the game ended it, such as k in a row in m,n,k
+	Resignation                    // a player resigned
+	DoublePass                     // both players passed in a row
+	BoardFull                      // there was no empty point left on the board
+	MoveLimit                      // the game reached the maximum number of moves
+)
+
+func (t Termination) String() string {
+	switch t {
+	case GameOver:
+		return "GameOver"
+	case Resignation:
+		return "Resignation"
+	case DoublePass:
+		return "DoublePass"
+	case BoardFull:
+		return "BoardFull"
+	case MoveLimit:
+		return "MoveLimit"
+	}
+	return "UNKNOWN TERMINATION"
+}
+
+// GameRecord is the record of a game played in an arena.
+type GameRecord struct {
+	Black, White string // names of the agents that played black and white
+
+	Winner      game.Player // None for a draw
+	BlackScore  float32     // final score of black, as given by the game
+	WhiteScore  float32     // final score of white, as given by the game
+	Termination Termination
+
+	Moves    []game.PlayerMove
+	Searches []mcts.SearchResult // the search of every move of Moves
+}
+
+// Margin returns the final score of p minus the final score of the opponent.
+func (r GameRecord) Margin(p game.Player) float32 {
+	if p == game.Player(game.White) {
+		return r.WhiteScore - r.BlackScore
+	}
+	return r.BlackScore - r.WhiteScore
+}
+
+// Outcome returns 1 if p won, -1 if p lost and 0 for a draw.
+func (r GameRecord) Outcome(p game.Player) float32 {
+	switch r.Winner {
+	case game.Player(game.None):
+		return 0
+	case p:
+		return 1
+	}
+	return -1
+}
+
+// ValueLabeller returns the value target of the position before the ith move of a game, from the point of view of the player
+// who made the move. Value targets are between -1 and 1.
+type ValueLabeller func(rec GameRecord, i int) float32
+
+// OutcomeLabeller labels every position with the outcome of the game. It is the labeller of AlphaZero.
+func OutcomeLabeller(rec GameRecord, i int) float32 { return rec.Outcome(rec.Moves[i].Player) }
+
+// ScoreMarginLabeller labels every position with the final score margin of the game, squashed by tanh(margin/scale).
+// It is meant for games such as Go, where winning by more is better.
+func ScoreMarginLabeller(scale float32) ValueLabeller {
+	return func(rec GameRecord, i int) float32 {
+		return math32.Tanh(rec.Margin(rec.Moves[i].Player) / scale)
+	}
+}
+
+// BootstrapLabeller labels a position with the value that the search found n moves later, in place of the outcome of the game.
+// Positions less than n moves from the end of the game are labelled with the outcome.
+func BootstrapLabeller(n int) ValueLabeller {
+	return func(rec GameRecord, i int) float32 {
+		player := rec.Moves[i].Player
+		if i+n >= len(rec.Searches) {
+			return rec.Outcome(player)
+		}
+		s := rec.Searches[i+n]
+		v := 2*s.Winrate - 1
+		if s.Player != player {
+			return -v
+		}
+		return v
+	}
+}
+
+// termination returns why the game of the arena has ended by its rules.
+func (a *Arena) termination(winner game.Player) Termination {
+	if winner != game.Player(game.None) {
+		return GameOver
+	}
+	for _, c := range a.game.Board() {
+		if c == game.None {
+			return GameOver
+		}
+	}
+	return BoardFull
+}
+
+// adjudicate returns the winner of g. A game that was stopped before its rules ended it is won by the best score.
+func adjudicate(g game.State) game.Player {
+	if ended, winner := g.Ended(); ended {
+		return winner
+	}
+	black, white := g.Score(game.Player(game.Black)), g.Score(game.Player(game.White))
+	switch {
+	case black > white:
+		return game.Player(game.Black)
+	case white > black:
+		return game.Player(game.White)
+	}
+	return game.Player(game.None)
+}
+
+func opponent(p game.Player) game.Player {
+	switch p {
+	case game.Player(game.Black):
+		return game.Player(game.White)
+	case game.Player(game.White):
+		return game.Player(game.Black)
+	}
+	return game.Player(game.None)
+}
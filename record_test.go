@@ -0,0 +1,81 @@
+package agogo
+
+import (
+	"testing"
+
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/game/mnk"
+	"github.com/gorgonia/agogo/mcts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueLabellers(t *testing.T) {
+	assert := assert.New(t)
+	black, white := game.Player(game.Black), game.Player(game.White)
+	rec := GameRecord{
+		Winner:     white,
+		BlackScore: 1,
+		WhiteScore: 4,
+		Moves: []game.PlayerMove{
+			{Player: black, Single: 0},
+			{Player: white, Single: 1},
+			{Player: black, Single: 2},
+		},
+		Searches: []mcts.SearchResult{
+			{Player: black, Winrate: 0.5},
+			{Player: white, Winrate: 0.75},
+			{Player: black, Winrate: 0.25},
+		},
+	}
+
+	assert.Equal(float32(-1), OutcomeLabeller(rec, 0))
+	assert.Equal(float32(1), OutcomeLabeller(rec, 1))
+	assert.Equal(float32(-1), OutcomeLabeller(rec, 2))
+
+	margin := ScoreMarginLabeller(3)
+	assert.InDelta(-0.7616, margin(rec, 0), 1e-4)
+	assert.InDelta(0.7616, margin(rec, 1), 1e-4)
+
+	bootstrap := BootstrapLabeller(1)
+	assert.Equal(float32(-0.5), bootstrap(rec, 0), "White expects to win after black's first move")
+	assert.Equal(float32(0.5), bootstrap(rec, 1), "Black expects to lose after white's move")
+	assert.Equal(float32(-1), bootstrap(rec, 2), "The last position is labelled with the outcome")
+
+	rec.Winner = game.Player(game.None)
+	assert.Equal(float32(0), OutcomeLabeller(rec, 0))
+}
+
+func TestArena_Play(t *testing.T) {
+	assert := assert.New(t)
+	conf := tictactoeConf()
+	conf.Seed = 1337
+	conf.MCTSConf.Deterministic = true
+	conf.MCTSConf.Budget = 50
+	a := New(mnk.TicTacToe(), conf)
+	a.setupSelfPlay(1)
+	defer a.A.Close()
+	defer a.B.Close()
+
+	rec, examples := a.Play(true, nil, nil)
+	ended, winner := a.game.Ended()
+	assert.True(ended)
+	assert.Equal(winner, rec.Winner, "The record should have the winner of the game")
+	if winner == game.Player(game.None) {
+		assert.Equal(BoardFull, rec.Termination)
+	} else {
+		assert.Equal(GameOver, rec.Termination)
+	}
+	assert.Equal(len(rec.Moves), len(rec.Searches))
+	assert.Equal(len(rec.Moves), len(examples))
+	for i, ex := range examples {
+		assert.Equal(rec.Outcome(rec.Moves[i].Player), ex.Value, "Example %d should be labelled for the player to move", i)
+		assert.Equal(rec.Moves[i].Player, rec.Searches[i].Player)
+	}
+	a.game.Reset()
+
+	a.maxMoves = 3
+	rec, _ = a.Play(false, nil, nil)
+	assert.Equal(MoveLimit, rec.Termination)
+	assert.Len(rec.Moves, 3)
+	assert.Equal(game.Player(game.None), rec.Winner, "Nobody can have won tic-tac-toe in 3 moves")
+}
@@ -0,0 +1,145 @@
+package agogo
+
+import (
+	"encoding/gob"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+
+	"github.com/gorgonia/agogo/mcts"
+	"github.com/pkg/errors"
+)
+
+// SamplingStrategy determines how training examples are sampled from a ReplayBuffer.
+type SamplingStrategy int
+
+const (
+	// UniformSampling samples every example in the buffer with equal probability.
+	UniformSampling SamplingStrategy = iota
+	// RecencySampling samples examples from newer generations more often than examples from older ones.
+	// The weight of a generation is linear in its age: the newest of N generations has weight N, the oldest has weight 1.
+	RecencySampling
+)
+
+// ReplayBuffer holds the self play examples of the last Window generations.
+// A generation is the set of examples created in one epoch of self play.
+type ReplayBuffer struct {
+	Window      int         // number of generations to keep
+	Generations [][]Example // oldest generation first
+
+	r *rand.Rand
+}
+
+// NewReplayBuffer creates a new replay buffer that keeps the last window generations of examples.
+// If window is less than 1, only the newest generation is kept.
+func NewReplayBuffer(window int) *ReplayBuffer {
+	if window < 1 {
+		window = 1
+	}
+	return &ReplayBuffer{
+		Window: window,
+		r:      mcts.NewRand(0),
+	}
+}
+
+// Push adds a generation of examples to the buffer. The oldest generations are evicted if there are more than Window generations.
+func (b *ReplayBuffer) Push(generation []Example) {
+	b.Generations = append(b.Generations, generation)
+	if extra := len(b.Generations) - b.Window; extra > 0 {
+		for i := 0; i < extra; i++ {
+			b.Generations[i] = nil // let the GC collect the examples
+		}
+		b.Generations = b.Generations[extra:]
+	}
+}
+
+// Len returns the number of examples in the buffer.
+func (b *ReplayBuffer) Len() (retVal int) {
+	for _, gen := range b.Generations {
+		retVal += len(gen)
+	}
+	return
+}
+
+// All returns all the examples in the buffer, oldest first.
+func (b *ReplayBuffer) All() []Example {
+	retVal := make([]Example, 0, b.Len())
+	for _, gen := range b.Generations {
+		retVal = append(retVal, gen...)
+	}
+	return retVal
+}
+
+// Sample samples n examples without replacement from the buffer. If n <= 0 or there are fewer than n examples in the buffer,
+// all the examples are returned.
+func (b *ReplayBuffer) Sample(n int, strategy SamplingStrategy) []Example {
+	all := b.All()
+	if n <= 0 || n >= len(all) {
+		return all
+	}
+
+	switch strategy {
+	case RecencySampling:
+		// weighted sampling without replacement (Efraimidis & Spirakis 2006):
+		// each example gets a key u^(1/w), and the n examples with the largest keys are picked.
+		keys := make([]keyedExample, 0, len(all))
+		var i int
+		for g, gen := range b.Generations {
+			w := float64(g + 1)
+			for range gen {
+				keys = append(keys, keyedExample{key: math.Pow(b.r.Float64(), 1/w), index: i})
+				i++
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i].key > keys[j].key })
+		retVal := make([]Example, n)
+		for i := range retVal {
+			retVal[i] = all[keys[i].index]
+		}
+		return retVal
+	default:
+		for i := 0; i < n; i++ {
+			j := i + b.r.Intn(len(all)-i)
+			all[i], all[j] = all[j], all[i]
+		}
+		return all[:n]
+	}
+}
+
+// Save writes the replay buffer to filename.
+func (b *ReplayBuffer) Save(filename string) error {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	enc := gob.NewEncoder(f)
+	return errors.WithStack(enc.Encode(b))
+}
+
+// Load reads a replay buffer that was written by Save. The contents of b are replaced.
+func (b *ReplayBuffer) Load(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	var loaded ReplayBuffer
+	dec := gob.NewDecoder(f)
+	if err = dec.Decode(&loaded); err != nil {
+		return errors.WithStack(err)
+	}
+	b.Window = loaded.Window
+	b.Generations = loaded.Generations
+	if b.r == nil {
+		b.r = mcts.NewRand(0)
+	}
+	return nil
+}
+
+type keyedExample struct {
+	key   float64
+	index int
+}
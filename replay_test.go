@@ -0,0 +1,74 @@
+package agogo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeGeneration(value float32, n int) []Example {
+	retVal := make([]Example, n)
+	for i := range retVal {
+		retVal[i] = Example{Board: []float32{float32(i)}, Policy: []float32{1}, Value: value}
+	}
+	return retVal
+}
+
+func TestReplayBuffer(t *testing.T) {
+	assert := assert.New(t)
+	b := NewReplayBuffer(3)
+	for g := 0; g < 5; g++ {
+		b.Push(makeGeneration(float32(g), 10))
+	}
+	assert.Equal(3, len(b.Generations), "Only the last 3 generations should be kept")
+	assert.Equal(30, b.Len())
+	assert.Equal(float32(2), b.Generations[0][0].Value, "The oldest generation should be generation 2")
+
+	assert.Equal(30, len(b.Sample(0, UniformSampling)), "n <= 0 should return everything")
+	assert.Equal(30, len(b.Sample(100, UniformSampling)), "n > Len() should return everything")
+
+	uniform := b.Sample(12, UniformSampling)
+	assert.Equal(12, len(uniform))
+
+	// recency sampling should favour the newest generation.
+	counts := make(map[float32]int)
+	for i := 0; i < 200; i++ {
+		for _, ex := range b.Sample(10, RecencySampling) {
+			counts[ex.Value]++
+		}
+	}
+	assert.True(counts[4] > counts[2], "Expected the newest generation to be sampled more than the oldest. Got %v", counts)
+
+	// a window less than 1 keeps only the newest generation
+	b1 := NewReplayBuffer(0)
+	b1.Push(makeGeneration(0, 2))
+	b1.Push(makeGeneration(1, 3))
+	assert.Equal(3, b1.Len())
+}
+
+func TestReplayBuffer_SaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "agogo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "replay")
+
+	b := NewReplayBuffer(2)
+	b.Push(makeGeneration(0, 4))
+	b.Push(makeGeneration(1, 5))
+	if err := b.Save(filename); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	b2 := NewReplayBuffer(1)
+	if err := b2.Load(filename); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	assert.Equal(t, b.Window, b2.Window)
+	assert.Equal(t, b.Generations, b2.Generations)
+	assert.Equal(t, 5, len(b2.Sample(5, RecencySampling)))
+}
@@ -0,0 +1,66 @@
+package agogo
+
+import (
+	"log"
+	"sync"
+)
+
+// selfPlay plays the given number of self play episodes and returns the examples generated.
+//
+// If more than one self play worker is configured, the episodes are played concurrently, each worker in its own forked Arena.
+// The examples are returned in the order of the episodes, regardless of which worker finishes first.
+// When the search is seeded, every episode is also seeded on its own, so the examples do not depend on the scheduling of the workers.
+func (a *AZ) selfPlay(episodes int) []Example {
+	if a.selfPlayWorkers <= 1 {
+		var ex []Example
+		for e := 0; e < episodes; e++ {
+			log.Printf("\tEpisode %v", e)
+			a.logger.Printf("Episode %v\n", e)
+			ex = append(ex, a.SelfPlay()...)
+		}
+		return ex
+	}
+
+	workers := a.selfPlayWorkers
+	if workers > episodes {
+		workers = episodes
+	}
+	// when seeded, every episode gets its own seed, so the games do not depend on which worker plays them
+	var seeds []int64
+	if a.conf.Seed != 0 {
+		seeds = make([]int64, episodes)
+		for e := range seeds {
+			seeds[e] = nextSeed(a.r)
+		}
+	}
+	results := make([][]Example, episodes)
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		ar := a.fork()
+		wg.Add(1)
+		go func(ar *Arena) {
+			defer wg.Done()
+			for e := range work {
+				log.Printf("\tEpisode %v", e)
+				if seeds != nil {
+					ar.reseed(seeds[e])
+				}
+				_, results[e] = ar.Play(true, nil, a.aug)
+				ar.game.Reset()
+			}
+		}(ar)
+	}
+	for e := 0; e < episodes; e++ {
+		a.logger.Printf("Episode %v\n", e)
+		work <- e
+	}
+	close(work)
+	wg.Wait()
+
+	var ex []Example
+	for _, r := range results {
+		ex = append(ex, r...)
+	}
+	return ex
+}
@@ -0,0 +1,90 @@
+package agogo
+
+import (
+	"testing"
+
+	"github.com/gorgonia/agogo/game/mnk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAZ_selfPlaySeeded(t *testing.T) {
+	play := func() []Example {
+		conf := tictactoeConf()
+		conf.Seed = 1337
+		conf.SelfPlayWorkers = 2
+		conf.MCTSConf.Deterministic = true
+		conf.MCTSConf.Budget = 50
+		conf.MCTSConf.RandomCount = 4
+		a := New(mnk.TicTacToe(), conf)
+		a.setupSelfPlay(1) // use the (seeded) networks, not the dummy inferers
+		defer a.A.Close()
+		defer a.B.Close()
+		return a.selfPlay(4)
+	}
+
+	ex := play()
+	if len(ex) == 0 {
+		t.Fatal("Expected examples")
+	}
+	assert.Equal(t, ex, play(), "The same seed should play the same games")
+
+	// the policy targets are the visits of the root's children, not the move that was played
+	var spread bool
+	for _, e := range ex {
+		var sum float32
+		var moves int
+		for _, p := range e.Policy {
+			sum += p
+			if p > 0 {
+				moves++
+			}
+		}
+		assert.InDelta(t, 1, sum, 1e-5, "The policy target should be a distribution: %v", e.Policy)
+		spread = spread || moves > 1
+	}
+	assert.True(t, spread, "The policy targets should not all be one-hot")
+}
+
+func TestAZ_selfPlayCached(t *testing.T) {
+	play := func(cacheSize int) (*AZ, []Example) {
+		conf := tictactoeConf()
+		conf.Seed = 1337
+		conf.SelfPlayWorkers = 2
+		conf.EvalCacheSize = cacheSize
+		conf.MCTSConf.Deterministic = true
+		conf.MCTSConf.Budget = 50
+		a := New(mnk.TicTacToe(), conf)
+		a.setupSelfPlay(1)
+		defer a.A.Close()
+		defer a.B.Close()
+		return a, a.selfPlay(4)
+	}
+
+	_, uncached := play(0)
+	a, cached := play(1 << 12)
+	assert.Equal(t, uncached, cached, "Caching evaluations should not change the games")
+	hits, _ := a.A.Cache.Stats()
+	assert.NotZero(t, hits, "Repeated positions should hit the cache")
+}
+
+func TestAZ_caches(t *testing.T) {
+	assert := assert.New(t)
+	conf := tictactoeConf()
+	conf.EvalCacheSize = 1 << 12
+	a := New(mnk.TicTacToe(), conf)
+	assert.NotNil(a.A.Cache)
+	assert.False(a.A.Cache == a.B.Cache, "A and B play with different networks")
+
+	// B is accepted as the next generation, and a new candidate takes its place
+	candidate := a.B.Cache
+	a.generation++
+	a.newCandidate()
+	assert.True(candidate == a.A.Cache, "The accepted network should keep its evaluations")
+	assert.False(a.A.Cache == a.B.Cache, "The new candidate should not use the cache of A")
+	assert.Equal(2, len(a.caches), "The caches of the older generations should be dropped")
+
+	// both agents play with the same network, so they share its cache
+	a.bgen = a.generation
+	a.setCaches()
+	assert.True(a.A.Cache == a.B.Cache)
+}
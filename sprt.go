@@ -0,0 +1,85 @@
+package agogo
+
+import "math"
+
+// SPRT is a sequential probability ratio test of the Elo difference between two players.
+//
+// The null hypothesis H0 is that the difference is Elo0, and the alternative hypothesis H1 is that it is Elo1.
+// Alpha and Beta are the probabilities of a false positive (accepting H1 when H0 holds) and of a false negative.
+type SPRT struct {
+	Elo0, Elo1  float64
+	Alpha, Beta float64
+}
+
+// SPRTDecision is the outcome of a SPRT.
+type SPRTDecision int
+
+const (
+	SPRTContinue SPRTDecision = iota // not enough games to decide
+	SPRTAcceptH0                     // the difference is Elo0 or worse
+	SPRTAcceptH1                     // the difference is Elo1 or better
+)
+
+func (d SPRTDecision) String() string {
+	switch d {
+	case SPRTContinue:
+		return "Continue"
+	case SPRTAcceptH0:
+		return "H0"
+	case SPRTAcceptH1:
+		return "H1"
+	}
+	return "UNKNOWN DECISION"
+}
+
+// SPRTResult is the state of a SPRT after a number of games.
+type SPRTResult struct {
+	LLR          float64 // log likelihood ratio
+	Lower, Upper float64 // bounds of the LLR
+	Decision     SPRTDecision
+}
+
+// expectedScore is the expected score of a player that is elo stronger than its opponent.
+func expectedScore(elo float64) float64 { return 1 / (1 + math.Pow(10, -elo/400)) }
+
+// Bounds returns the bounds of the log likelihood ratio. H0 is accepted below the lower bound, and H1 above the upper bound.
+func (s SPRT) Bounds() (lower, upper float64) {
+	return math.Log(s.Beta / (1 - s.Alpha)), math.Log((1 - s.Beta) / s.Alpha)
+}
+
+// LLR returns the log likelihood ratio of H1 against H0 given the results of a player.
+//
+// It uses the normal approximation of the trinomial (win, draw, loss) model. The approximation breaks down when the variance
+// of the results is 0, for instance when every game is a draw, so the results are regularized with half a win and half a loss.
+func (s SPRT) LLR(wins, losses, draws float64) float64 {
+	if wins+losses+draws == 0 {
+		return 0
+	}
+	wins, losses = wins+0.5, losses+0.5
+	n := wins + losses + draws
+	score := (wins + draws/2) / n
+	variance := (wins+draws/4)/n - score*score
+	if variance <= 0 {
+		return 0
+	}
+	s0, s1 := expectedScore(s.Elo0), expectedScore(s.Elo1)
+	return (s1 - s0) * (2*score - s0 - s1) / (2 * variance / n)
+}
+
+// Test runs the test on the results of a player.
+func (s SPRT) Test(wins, losses, draws float64) SPRTResult {
+	retVal := SPRTResult{LLR: s.LLR(wins, losses, draws)}
+	retVal.Lower, retVal.Upper = s.Bounds()
+	switch {
+	case retVal.LLR >= retVal.Upper:
+		retVal.Decision = SPRTAcceptH1
+	case retVal.LLR <= retVal.Lower:
+		retVal.Decision = SPRTAcceptH0
+	}
+	return retVal
+}
+
+// IsValid returns true if the test can be run.
+func (s SPRT) IsValid() bool {
+	return s.Elo1 > s.Elo0 && s.Alpha > 0 && s.Alpha < 1 && s.Beta > 0 && s.Beta < 1
+}
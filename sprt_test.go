@@ -0,0 +1,29 @@
+package agogo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSPRT(t *testing.T) {
+	assert := assert.New(t)
+	s := SPRT{Elo0: 0, Elo1: 50, Alpha: 0.05, Beta: 0.05}
+	assert.True(s.IsValid())
+	assert.False(SPRT{Elo0: 10, Elo1: 0, Alpha: 0.05, Beta: 0.05}.IsValid())
+
+	lower, upper := s.Bounds()
+	assert.InDelta(-math.Log(19), lower, 1e-9)
+	assert.InDelta(math.Log(19), upper, 1e-9)
+
+	assert.Equal(0.0, s.LLR(0, 0, 0), "No games, no evidence")
+	assert.Equal(SPRTContinue, s.Test(5, 5, 5).Decision)
+
+	// a 75% score over many games is overwhelming evidence for H1, and a 25% score for H0
+	assert.Equal(SPRTAcceptH1, s.Test(300, 100, 0).Decision)
+	assert.Equal(SPRTAcceptH0, s.Test(100, 300, 0).Decision)
+
+	// more games with the same score should give more evidence
+	assert.True(s.LLR(60, 40, 0) > s.LLR(6, 4, 0))
+}
@@ -2,16 +2,33 @@ package agogo
 
 import (
 	"encoding/csv"
-	"fmt"
 	"os"
 	"strconv"
+
+	dual "github.com/gorgonia/agogo/dualnet"
 )
 
+// Statistics are the statistics of a training run.
+//
+// Wins, Losses and Draws are the results of every arena that a generation played as the incumbent, keyed by the name of the generation.
 type Statistics struct {
 	Creation []string
 	Wins     map[string][]float32
 	Losses   map[string][]float32
 	Draws    map[string][]float32
+
+	// Ratings holds the Elo ratings of the accepted generations, in order of acceptance.
+	Ratings []Rating
+
+	// Training holds the average training metrics of every pass through the examples, in every epoch. The metrics of the batches
+	// are only kept for the latest epoch, each pass being preceded by its batches, so that the history does not grow with every batch.
+	Training []TrainingMetrics
+}
+
+// TrainingMetrics are the metrics of a batch, or the average metrics of a pass (see IsPass), of training the candidate network in an epoch.
+type TrainingMetrics struct {
+	Epoch int
+	dual.Metrics
 }
 
 func makeStatistics() Statistics {
@@ -23,8 +40,8 @@ func makeStatistics() Statistics {
 	}
 }
 
-func (s *Statistics) update(A *Agent) {
-	aname := fmt.Sprintf("%p", A.NN)
+func (s *Statistics) update(gen int, A *Agent) {
+	aname := generationName(gen)
 
 	if _, ok := s.Wins[aname]; !ok {
 		s.Creation = append(s.Creation, aname)
@@ -35,7 +52,24 @@ func (s *Statistics) update(A *Agent) {
 	s.Draws[aname] = append(s.Draws[aname], A.Draw)
 }
 
-// Dump the statistics in filename using a CSV format
+// recordTraining records the training metrics of a batch or a pass. The batches of the previous epochs are dropped once a new epoch starts.
+func (s *Statistics) recordTraining(epoch int, m dual.Metrics) {
+	if n := len(s.Training); n > 0 && s.Training[n-1].Epoch != epoch {
+		passes := s.Training[:0]
+		for _, t := range s.Training {
+			if t.IsPass() {
+				passes = append(passes, t)
+			}
+		}
+		s.Training = passes
+	}
+	s.Training = append(s.Training, TrainingMetrics{Epoch: epoch, Metrics: m})
+}
+
+// Dump the statistics in filename using a CSV format.
+//
+// The win rates of the generations come first. They are followed by a header and the training metrics, with one row for
+// the average of every pass, which has a batch of -1, and one row for every batch of the latest epoch.
 func (s *Statistics) Dump(filename string) error {
 	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
 	if err != nil {
@@ -59,6 +93,40 @@ func (s *Statistics) Dump(filename string) error {
 	if err := w.WriteAll(records); err != nil {
 		return err
 	}
+	if len(s.Training) > 0 {
+		if err := s.writeTraining(w); err != nil {
+			return err
+		}
+	}
 	w.Flush()
+	return w.Error()
+}
+
+// writeTraining writes the training metrics, preceded by a header.
+func (s *Statistics) writeTraining(w *csv.Writer) error {
+	if err := w.Write([]string{"epoch", "iteration", "batch", "step", "learn_rate", "policy_loss", "value_loss", "l2", "loss", "grad_norm", "val_policy_loss", "val_value_loss", "val_loss"}); err != nil {
+		return err
+	}
+	f32 := func(a float32) string { return strconv.FormatFloat(float64(a), 'f', 6, 32) }
+	for _, m := range s.Training {
+		record := []string{
+			strconv.Itoa(m.Epoch),
+			strconv.Itoa(m.Iteration),
+			strconv.Itoa(m.Batch),
+			strconv.Itoa(m.Step),
+			strconv.FormatFloat(m.LearnRate, 'g', 6, 64),
+			f32(m.PolicyLoss),
+			f32(m.ValueLoss),
+			f32(m.L2),
+			f32(m.Loss),
+			f32(m.GradNorm),
+			f32(m.ValPolicyLoss),
+			f32(m.ValValueLoss),
+			f32(m.ValLoss),
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
 	return nil
 }
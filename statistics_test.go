@@ -0,0 +1,65 @@
+package agogo
+
+import (
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dual "github.com/gorgonia/agogo/dualnet"
+	"github.com/gorgonia/agogo/game/mnk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatistics_Dump(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "agogo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "stats.csv")
+
+	a := New(mnk.TicTacToe(), tictactoeConf())
+	a.Statistics.update(0, a.A)
+	a.epoch = 1
+	a.reportTraining(dual.Metrics{Iteration: 0, Batch: 0, Loss: 2})
+	a.reportTraining(dual.Metrics{Iteration: 0, Batch: 1, Loss: 1})
+	a.reportTraining(dual.Metrics{Iteration: 0, Batch: -1, Loss: 1.5})
+	assert.Equal(3, len(a.Statistics.Training), "Every batch and every pass should be recorded")
+	a.epoch = 2
+	a.reportTraining(dual.Metrics{Iteration: 0, Batch: 0, Loss: 1})
+	a.reportTraining(dual.Metrics{Iteration: 0, Batch: -1, Loss: 1})
+	assert.Equal(3, len(a.Statistics.Training), "Only the passes of the previous epochs should be kept")
+
+	if err := a.Statistics.Dump(filename); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	raw, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotContains(string(raw), "\n\n", "There should be no blank record")
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the generations and their win rate, then the header of the training metrics and a row for every entry
+	if !assert.Equal(2+1+3, len(records), "%v", records) {
+		return
+	}
+	assert.Equal([]string{generationName(0)}, records[0])
+	assert.Equal("epoch", records[2][0])
+	assert.Equal([]string{"1", "0", "-1"}, records[3][:3], "The pass of the previous epoch should be kept")
+	assert.Equal("1.500000", records[3][8])
+	assert.Equal([]string{"2", "0", "-1"}, records[5][:3], "The average of the pass should follow its batches")
+}
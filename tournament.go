@@ -0,0 +1,313 @@
+package agogo
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/gorgonia/agogo/game"
+	"github.com/gorgonia/agogo/mcts"
+	"github.com/pkg/errors"
+)
+
+// TournamentFormat is the format of a tournament.
+type TournamentFormat int
+
+const (
+	RoundRobin TournamentFormat = iota // every contestant plays every other contestant
+	Gauntlet                           // the first contestant plays every other contestant
+)
+
+func (f TournamentFormat) String() string {
+	switch f {
+	case RoundRobin:
+		return "RoundRobin"
+	case Gauntlet:
+		return "Gauntlet"
+	}
+	return "UNKNOWN FORMAT"
+}
+
+// TournamentConfig is the configuration of a tournament.
+type TournamentConfig struct {
+	Name     string
+	MCTSConf mcts.Config
+	Format   TournamentFormat
+	Games    int  // number of games of every pairing. The contestants of a pairing alternate colours
+	SPRT     SPRT // if valid, every pairing is tested with it
+	MaxMoves int  // if > 0, games are stopped after this many moves, and adjudicated by score
+
+	OutputEncoder OutputEncoder
+}
+
+// Contestant is a named player that takes part in a tournament.
+type Contestant struct {
+	Name   string
+	Player Player
+}
+
+// Tournament plays games between any number of players, and rates them.
+type Tournament struct {
+	TournamentConfig
+	game        game.State
+	contestants []Contestant
+}
+
+// NewTournament creates a new tournament of game g.
+func NewTournament(g game.State, conf TournamentConfig) *Tournament {
+	return &Tournament{
+		TournamentConfig: conf,
+		game:             g,
+	}
+}
+
+// Add adds a contestant to the tournament. In a gauntlet, the first contestant added plays against all the others.
+// Agents search with the MCTSConf of the tournament.
+func (t *Tournament) Add(name string, p Player) {
+	if agent, ok := p.(*Agent); ok {
+		agent.name = name
+	}
+	t.contestants = append(t.contestants, Contestant{Name: name, Player: p})
+}
+
+// Score is the results of a player against an opponent.
+type Score struct {
+	Wins, Losses, Draws int
+}
+
+// Games returns the number of games played.
+func (s Score) Games() int { return s.Wins + s.Losses + s.Draws }
+
+// WinRate returns the score of the player, counting draws as half a win.
+func (s Score) WinRate() float64 {
+	if s.Games() == 0 {
+		return 0
+	}
+	return (float64(s.Wins) + float64(s.Draws)/2) / float64(s.Games())
+}
+
+// Pairing is the result of the games between two contestants, from the point of view of contestant A.
+type Pairing struct {
+	A, B int // indices of the contestants
+	Score
+	SPRT SPRTResult
+}
+
+// TournamentResult is the result of a tournament.
+type TournamentResult struct {
+	Names    []string
+	Table    [][]Score // cross table. Table[i][j] is the score of contestant i against contestant j
+	Pairings []Pairing
+	Elo      []float64 // Bradley-Terry ratings of the contestants, with an average of 0
+}
+
+// Run plays all the games of the tournament.
+func (t *Tournament) Run() (*TournamentResult, error) {
+	if len(t.contestants) < 2 {
+		return nil, errors.Errorf("A tournament needs at least 2 contestants. Got %d", len(t.contestants))
+	}
+	if t.Games < 1 {
+		return nil, errors.Errorf("Expected at least 1 game per pairing. Got %d", t.Games)
+	}
+	n := len(t.contestants)
+	retVal := &TournamentResult{
+		Names: make([]string, n),
+		Table: make([][]Score, n),
+	}
+	for i, c := range t.contestants {
+		retVal.Names[i] = c.Name
+		retVal.Table[i] = make([]Score, n)
+	}
+
+	for _, p := range t.pairings() {
+		pairing, err := t.play(p[0], p[1])
+		if err != nil {
+			return nil, err
+		}
+		retVal.Pairings = append(retVal.Pairings, pairing)
+		retVal.Table[p[0]][p[1]] = pairing.Score
+		retVal.Table[p[1]][p[0]] = Score{Wins: pairing.Losses, Losses: pairing.Wins, Draws: pairing.Draws}
+		log.Printf("%v vs %v: +%d -%d =%d", retVal.Names[p[0]], retVal.Names[p[1]], pairing.Wins, pairing.Losses, pairing.Draws)
+	}
+	retVal.Elo = bradleyTerry(retVal.Table)
+	return retVal, nil
+}
+
+// pairings returns the pairs of contestants that play each other.
+func (t *Tournament) pairings() (retVal [][2]int) {
+	n := len(t.contestants)
+	switch t.Format {
+	case Gauntlet:
+		for j := 1; j < n; j++ {
+			retVal = append(retVal, [2]int{0, j})
+		}
+	default:
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				retVal = append(retVal, [2]int{i, j})
+			}
+		}
+	}
+	return
+}
+
+// play plays the games between contestants i and j in an arena. They alternate colours, and i plays black first.
+func (t *Tournament) play(i, j int) (Pairing, error) {
+	g := t.game.Clone()
+	A, B := t.contestants[i].Player, t.contestants[j].Player
+	arena := &Arena{
+		r:        mcts.NewRand(t.MCTSConf.Seed),
+		game:     g,
+		conf:     t.MCTSConf,
+		name:     t.Name,
+		maxMoves: t.MaxMoves,
+	}
+	arena.logger = log.New(&arena.buf, "", log.Ltime)
+	for _, p := range []Player{A, B} {
+		if agent, ok := p.(*Agent); ok {
+			agent.MCTS = agent.newMCTS(g, arena.mctsConf())
+		}
+	}
+
+	// games between two agents are played like the arena games of training. Other players may fail, so they play matches
+	agentA, okA := A.(*Agent)
+	agentB, okB := B.(*Agent)
+	if okA && okB {
+		arena.A, arena.B = agentA, agentB
+		arena.alternate = true
+	}
+
+	retVal := Pairing{A: i, B: j}
+	for arena.gameNumber = 0; arena.gameNumber < t.Games; arena.gameNumber++ {
+		black, white := A, B
+		if arena.gameNumber%2 == 1 {
+			black, white = B, A
+		}
+		var rec GameRecord
+		var err error
+		if arena.A != nil {
+			rec, _ = arena.Play(false, t.OutputEncoder, nil)
+		} else {
+			rec, err = arena.Match(black, white, t.OutputEncoder)
+		}
+		if err != nil {
+			return retVal, errors.WithMessage(err, fmt.Sprintf("Game %d of %v against %v", arena.gameNumber, t.contestants[i].Name, t.contestants[j].Name))
+		}
+		switch {
+		case rec.Winner == game.Player(game.None):
+			retVal.Draws++
+		case (rec.Winner == game.Player(game.Black)) == (black == A):
+			retVal.Wins++
+		default:
+			retVal.Losses++
+		}
+		arena.game.Reset()
+	}
+	if t.SPRT.IsValid() {
+		retVal.SPRT = t.SPRT.Test(float64(retVal.Wins), float64(retVal.Losses), float64(retVal.Draws))
+	}
+	return retVal, nil
+}
+
+// bradleyTerry computes the Bradley-Terry ratings of the players of a cross table, as Elo with an average of 0.
+//
+// Draws count as half a win for each player. Every pair of players that played each other gets one virtual draw,
+// so that a player that has lost every game still has a finite rating.
+func bradleyTerry(table [][]Score) []float64 {
+	n := len(table)
+	wins := make([]float64, n)
+	games := make([][]float64, n)
+	for i := range table {
+		games[i] = make([]float64, n)
+		for j, s := range table[i] {
+			if s.Games() == 0 {
+				continue
+			}
+			wins[i] += float64(s.Wins) + float64(s.Draws)/2 + 0.5
+			games[i][j] = float64(s.Games()) + 1
+		}
+	}
+
+	γ := make([]float64, n)
+	for i := range γ {
+		γ[i] = 1
+	}
+	next := make([]float64, n)
+	for iter := 0; iter < 10000; iter++ {
+		var delta float64
+		for i := range γ {
+			var denom float64
+			for j := range γ {
+				if games[i][j] > 0 {
+					denom += games[i][j] / (γ[i] + γ[j])
+				}
+			}
+			if denom == 0 {
+				next[i] = γ[i]
+				continue
+			}
+			next[i] = wins[i] / denom
+		}
+		// normalize so that the geometric mean is 1
+		var logMean float64
+		for _, g := range next {
+			logMean += math.Log(g)
+		}
+		norm := math.Exp(logMean / float64(n))
+		for i := range next {
+			next[i] /= norm
+			delta = math.Max(delta, math.Abs(next[i]-γ[i]))
+		}
+		γ, next = next, γ
+		if delta < 1e-10 {
+			break
+		}
+	}
+
+	retVal := make([]float64, n)
+	for i, g := range γ {
+		retVal[i] = 400 * math.Log10(g)
+	}
+	return retVal
+}
+
+// WriteTable writes the cross table, the win rates and the ratings of the contestants to w.
+func (r *TournamentResult) WriteTable(w io.Writer) error {
+	width := 6
+	for _, name := range r.Names {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-*s", width, "")
+	for _, name := range r.Names {
+		fmt.Fprintf(&buf, " | %*s", width, name)
+	}
+	fmt.Fprintf(&buf, " | %8s | %8s\n", "Win Rate", "Elo")
+	for i, row := range r.Table {
+		fmt.Fprintf(&buf, "%-*s", width, r.Names[i])
+		var total Score
+		for j, s := range row {
+			if i == j || s.Games() == 0 {
+				fmt.Fprintf(&buf, " | %*s", width, "-")
+				continue
+			}
+			fmt.Fprintf(&buf, " | %*s", width, fmt.Sprintf("%d-%d-%d", s.Wins, s.Losses, s.Draws))
+			total.Wins += s.Wins
+			total.Losses += s.Losses
+			total.Draws += s.Draws
+		}
+		fmt.Fprintf(&buf, " | %8.3f | %8.1f\n", total.WinRate(), r.Elo[i])
+	}
+	for _, p := range r.Pairings {
+		if p.SPRT.Lower == 0 && p.SPRT.Upper == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "SPRT %v vs %v: LLR %.2f (%.2f, %.2f) %v\n", r.Names[p.A], r.Names[p.B], p.SPRT.LLR, p.SPRT.Lower, p.SPRT.Upper, p.SPRT.Decision)
+	}
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
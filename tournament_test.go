@@ -0,0 +1,67 @@
+package agogo
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gorgonia/agogo/game/mnk"
+	"github.com/gorgonia/agogo/mcts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBradleyTerry(t *testing.T) {
+	assert := assert.New(t)
+	table := [][]Score{
+		{{}, {Wins: 3, Losses: 1}, {Wins: 4}},
+		{{Wins: 1, Losses: 3}, {}, {Wins: 3, Losses: 1}},
+		{{Losses: 4}, {Wins: 1, Losses: 3}, {}},
+	}
+	elo := bradleyTerry(table)
+	assert.True(elo[0] > elo[1] && elo[1] > elo[2], "Ratings should follow the results: %v", elo)
+	assert.InDelta(0, elo[0]+elo[1]+elo[2], 1e-6, "Ratings should average to 0")
+	for _, e := range elo {
+		assert.False(math.IsNaN(e) || math.IsInf(e, 0), "Ratings should be finite")
+	}
+}
+
+func TestTournament(t *testing.T) {
+	assert := assert.New(t)
+	g := mnk.TicTacToe()
+	conf := mcts.DefaultConfig(3)
+	conf.Timeout = 10 * time.Millisecond
+	conf.Budget = 50
+
+	tour := NewTournament(g, TournamentConfig{
+		Name:     "Tic Tac Toe",
+		MCTSConf: conf,
+		Format:   RoundRobin,
+		Games:    2,
+		SPRT:     SPRT{Elo0: 0, Elo1: 50, Alpha: 0.05, Beta: 0.05},
+	})
+	for _, name := range []string{"x", "y", "z"} {
+		tour.Add(name, NewInfererAgent(dummyInferer{outputSize: g.ActionSpace()}, encodeTicTacToe))
+	}
+	res, err := tour.Run()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	assert.Equal([]string{"x", "y", "z"}, res.Names)
+	assert.Equal(3, len(res.Pairings), "Every contestant should play every other contestant")
+	for _, p := range res.Pairings {
+		assert.Equal(2, p.Games())
+		s := res.Table[p.B][p.A]
+		assert.Equal(p.Wins, s.Losses, "The cross table should be symmetric")
+		assert.Equal(p.Draws, s.Draws)
+	}
+	assert.Equal(3, len(res.Elo))
+
+	var buf bytes.Buffer
+	assert.NoError(res.WriteTable(&buf))
+	assert.Contains(buf.String(), "Win Rate")
+
+	gauntlet := NewTournament(g, TournamentConfig{MCTSConf: conf, Format: Gauntlet, Games: 1})
+	gauntlet.contestants = tour.contestants
+	assert.Equal([][2]int{{0, 1}, {0, 2}}, gauntlet.pairings())
+}
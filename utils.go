@@ -3,6 +3,7 @@ package agogo
 import (
 	"bytes"
 	"fmt"
+	"math/rand"
 
 	"github.com/pkg/errors"
 	"gorgonia.org/tensor"
@@ -54,3 +55,6 @@ func (err manyErr) Error() string {
 	}
 	return buf.String()
 }
+
+// nextSeed draws a new seed from r. The seed is never 0, which would mean seeding from the clock.
+func nextSeed(r *rand.Rand) int64 { return r.Int63() + 1 }